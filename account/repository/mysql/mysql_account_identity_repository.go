@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlAccountIdentityRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlAccountIdentityRepository(db *sql.DB) domain.IAccountIdentityRepository {
+	return &MySqlAccountIdentityRepository{
+		Db: db,
+	}
+}
+
+func (ir MySqlAccountIdentityRepository) GetIdentity(provider, subject string) (*domain.AccountIdentity, error) {
+	query := sq.Select("provider, subject, account_id, created_at").
+		From("account_identity").
+		Where(sq.Eq{
+			"provider": provider,
+			"subject":  subject,
+		})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GAI00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var identity domain.AccountIdentity
+	err = ir.Db.QueryRow(sqlString, args...).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.AccountID,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GAI01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &identity, nil
+}
+
+func (ir MySqlAccountIdentityRepository) InsertIdentity(identity domain.AccountIdentity) error {
+	/*start create query*/
+	query := sq.Insert("account_identity").
+		Columns("provider", "subject", "account_id", "created_at").
+		Values(identity.Provider, identity.Subject, identity.AccountID, time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAI00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := ir.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAI01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IAI02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IAI03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAI04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
@@ -0,0 +1,223 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlPasskeyRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlPasskeyRepository(db *sql.DB) domain.IPasskeyRepository {
+	return &MySqlPasskeyRepository{
+		Db: db,
+	}
+}
+
+func (pr MySqlPasskeyRepository) InsertPasskey(passkey domain.Passkey) error {
+	/*start create query*/
+	query := sq.Insert("account_passkey").
+		Columns("credential_id", "account_id", "name", "public_key", "sign_count", "transports", "created_at").
+		Values(passkey.CredentialID, passkey.AccountID, passkey.Name, passkey.PublicKey,
+			passkey.SignCount, strings.Join(passkey.Transports, ","), time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IPK00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := pr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IPK01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IPK02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IPK03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IPK04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (pr MySqlPasskeyRepository) GetPasskey(credentialID []byte) (*domain.Passkey, error) {
+	query := pr.passkeyQuery().
+		Where(sq.Eq{"credential_id": credentialID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GPK00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var passkey domain.Passkey
+	err = pr.scanPasskey(pr.Db.QueryRow(sqlString, args...), &passkey)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GPK01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &passkey, nil
+}
+
+func (pr MySqlPasskeyRepository) PasskeyList(accountID string) ([]domain.Passkey, error) {
+	query := pr.passkeyQuery().
+		Where(sq.Eq{"account_id": accountID}).
+		OrderBy("created_at ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PKL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := pr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PKL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	passkeyList := []domain.Passkey{}
+	for rows.Next() {
+		var passkey domain.Passkey
+		err = pr.scanPasskey(rows, &passkey)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("PKL02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		passkeyList = append(passkeyList, passkey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("PKL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return passkeyList, nil
+}
+
+func (pr MySqlPasskeyRepository) UpdateName(credentialID []byte, accountID, name string) error {
+	query := sq.Update("account_passkey").
+		Set("name", name).
+		Where(sq.Eq{
+			"credential_id": credentialID,
+			"account_id":    accountID,
+		})
+
+	return pr.exec("UKN", query)
+}
+
+func (pr MySqlPasskeyRepository) DeletePasskey(credentialID []byte, accountID string) error {
+	query := sq.Delete("account_passkey").
+		Where(sq.Eq{
+			"credential_id": credentialID,
+			"account_id":    accountID,
+		})
+
+	return pr.exec("DPK", query)
+}
+
+// UpdateSignCount does not check the sign count, the caller compares it
+// with the one it read. Affected rows can not tell, a counter-less
+// authenticator used twice in a second changes no column
+func (pr MySqlPasskeyRepository) UpdateSignCount(credentialID []byte, signCount uint32) error {
+	query := sq.Update("account_passkey").
+		Set("sign_count", signCount).
+		Set("last_used_at", time.Now()).
+		Where(sq.Eq{"credential_id": credentialID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("USC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = pr.Db.Exec(sql, args...)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("USC01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+// exec runs an update or delete of a passkey owned by the account,
+// no affected row means the passkey is not found
+func (pr MySqlPasskeyRepository) exec(tag string, query sq.Sqlizer) error {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	result, err := pr.Db.Exec(sql, args...)
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if affected == 0 {
+		cerr := cerror.NewAndPrintWithTag(tag+"03", errors.New("passkey is not found"), global.FRIENDLY_PASSKEY_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return cerr
+	}
+
+	return nil
+}
+
+func (pr MySqlPasskeyRepository) passkeyQuery() sq.SelectBuilder {
+	return sq.Select("credential_id, account_id, name, public_key, sign_count, transports, created_at, last_used_at").
+		From("account_passkey")
+}
+
+func (pr MySqlPasskeyRepository) scanPasskey(row interface{ Scan(...interface{}) error }, passkey *domain.Passkey) error {
+	var (
+		transports string
+		lastUsedAt sql.NullTime
+	)
+	err := row.Scan(
+		&passkey.CredentialID,
+		&passkey.AccountID,
+		&passkey.Name,
+		&passkey.PublicKey,
+		&passkey.SignCount,
+		&transports,
+		&passkey.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if transports != "" {
+		passkey.Transports = strings.Split(transports, ",")
+	}
+	if lastUsedAt.Valid {
+		passkey.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlSessionRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlSessionRepository(db *sql.DB) domain.ISessionRepository {
+	return &MySqlSessionRepository{
+		Db: db,
+	}
+}
+
+func (sr MySqlSessionRepository) InsertSession(session domain.Session) error {
+	/*start create query*/
+	query := sq.Insert("account_session").
+		Columns("session_id", "account_id", "device_name", "ip_address", "user_agent",
+			"access_uuid", "refresh_uuid", "created_at", "last_used_at", "expires_at").
+		Values(session.SessionID, session.AccountID, session.DeviceName, session.IPAddress, session.UserAgent,
+			session.AccessUUID, session.RefreshUUID, session.CreatedAt, session.LastUsedAt, session.ExpiresAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ISS00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := sr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ISS01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("ISS02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("ISS03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ISS04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (sr MySqlSessionRepository) GetSession(sessionID string) (*domain.Session, error) {
+	query := sr.sessionQuery().
+		Where(sq.Eq{"session_id": sessionID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GSS00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var session domain.Session
+	err = sr.scanSession(sr.Db.QueryRow(sqlString, args...), &session)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GSS01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &session, nil
+}
+
+func (sr MySqlSessionRepository) SessionList(accountID string) ([]domain.Session, error) {
+	query := sr.sessionQuery().
+		Where(sq.Eq{"account_id": accountID}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		OrderBy("last_used_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("SSL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := sr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("SSL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	sessionList := []domain.Session{}
+	for rows.Next() {
+		var session domain.Session
+		err = sr.scanSession(rows, &session)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("SSL02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		sessionList = append(sessionList, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("SSL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return sessionList, nil
+}
+
+func (sr MySqlSessionRepository) UpdateSession(session domain.Session) error {
+	query := sq.Update("account_session").
+		Set("ip_address", session.IPAddress).
+		Set("user_agent", session.UserAgent).
+		Set("access_uuid", session.AccessUUID).
+		Set("refresh_uuid", session.RefreshUUID).
+		Set("last_used_at", session.LastUsedAt).
+		Set("expires_at", session.ExpiresAt).
+		Where(sq.Eq{"session_id": session.SessionID})
+
+	return sr.exec("USS", query)
+}
+
+func (sr MySqlSessionRepository) DeleteSession(sessionID string) error {
+	query := sq.Delete("account_session").
+		Where(sq.Eq{"session_id": sessionID})
+
+	return sr.exec("DSS", query)
+}
+
+func (sr MySqlSessionRepository) DeleteExpiredSessions(accountID string) error {
+	query := sq.Delete("account_session").
+		Where(sq.Eq{"account_id": accountID}).
+		Where(sq.LtOrEq{"expires_at": time.Now()})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DES00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = sr.Db.Exec(sql, args...)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DES01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+// exec runs an update or delete of a single session, no affected row
+// means the session is not found
+func (sr MySqlSessionRepository) exec(tag string, query sq.Sqlizer) error {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	result, err := sr.Db.Exec(sql, args...)
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if affected == 0 {
+		cerr := cerror.NewAndPrintWithTag(tag+"03", errors.New("session is not found"), global.FRIENDLY_SESSION_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return cerr
+	}
+
+	return nil
+}
+
+func (sr MySqlSessionRepository) sessionQuery() sq.SelectBuilder {
+	return sq.Select("session_id, account_id, device_name, ip_address, user_agent, access_uuid, refresh_uuid, created_at, last_used_at, expires_at").
+		From("account_session")
+}
+
+func (sr MySqlSessionRepository) scanSession(row interface{ Scan(...interface{}) error }, session *domain.Session) error {
+	return row.Scan(
+		&session.SessionID,
+		&session.AccountID,
+		&session.DeviceName,
+		&session.IPAddress,
+		&session.UserAgent,
+		&session.AccessUUID,
+		&session.RefreshUUID,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+	)
+}
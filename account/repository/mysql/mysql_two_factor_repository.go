@@ -0,0 +1,244 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlTwoFactorRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlTwoFactorRepository(db *sql.DB) domain.ITwoFactorRepository {
+	return &MySqlTwoFactorRepository{
+		Db: db,
+	}
+}
+
+func (tr MySqlTwoFactorRepository) GetTwoFactor(accountID string) (*domain.TwoFactor, error) {
+	query := sq.Select("account_id, secret, is_enabled, last_used_step, created_at, enabled_at").
+		From("account_two_factor").
+		Where(sq.Eq{"account_id": accountID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GTF00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var (
+		twoFactor domain.TwoFactor
+		enabledAt sql.NullTime
+	)
+	err = tr.Db.QueryRow(sqlString, args...).Scan(
+		&twoFactor.AccountID,
+		&twoFactor.Secret,
+		&twoFactor.IsEnabled,
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+		&enabledAt,
+	)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GTF01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if enabledAt.Valid {
+		twoFactor.EnabledAt = &enabledAt.Time
+	}
+
+	return &twoFactor, nil
+}
+
+// SaveSecret never replaces the secret of an enabled two-factor
+func (tr MySqlTwoFactorRepository) SaveSecret(twoFactor domain.TwoFactor) error {
+	/*start create query*/
+	query := sq.Insert("account_two_factor").
+		Columns("account_id", "secret", "is_enabled", "last_used_step", "created_at").
+		Values(twoFactor.AccountID, twoFactor.Secret, false, 0, time.Now()).
+		Suffix(`ON DUPLICATE KEY UPDATE
+			secret = IF(is_enabled, secret, VALUES(secret)),
+			created_at = IF(is_enabled, created_at, VALUES(created_at))`)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("STF00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := tr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("STF01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("STF02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("STF03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("STF04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (tr MySqlTwoFactorRepository) EnableTwoFactor(accountID string, recoveryCodeHashes []string) error {
+	/*start create query*/
+	now := time.Now()
+	enableQuery := sq.Update("account_two_factor").
+		Set("is_enabled", true).
+		Set("enabled_at", now).
+		Where(sq.Eq{"account_id": accountID})
+
+	enableSql, enableArgs, err := enableQuery.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ETF00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	deleteQuery := sq.Delete("account_recovery_code").
+		Where(sq.Eq{"account_id": accountID})
+
+	deleteSql, deleteArgs, err := deleteQuery.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ETF01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	codeQuery := sq.Insert("account_recovery_code").
+		Columns("account_id", "code_hash", "created_at")
+	for _, codeHash := range recoveryCodeHashes {
+		codeQuery = codeQuery.Values(accountID, codeHash, now)
+	}
+
+	codeSql, codeArgs, err := codeQuery.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ETF02", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	tx, err := tr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ETF03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(enableSql, enableArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("ETF04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(deleteSql, deleteArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("ETF05", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(codeSql, codeArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("ETF06", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("ETF07", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (tr MySqlTwoFactorRepository) DeleteTwoFactor(accountID string) error {
+	/*start create query*/
+	codeSql, codeArgs, err := sq.Delete("account_recovery_code").
+		Where(sq.Eq{"account_id": accountID}).
+		ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DTF00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	twoFactorSql, twoFactorArgs, err := sq.Delete("account_two_factor").
+		Where(sq.Eq{"account_id": accountID}).
+		ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DTF01", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	tx, err := tr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DTF02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(codeSql, codeArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DTF03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(twoFactorSql, twoFactorArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DTF04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DTF05", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+// UseStep only moves last_used_step forward so a code can not be replayed
+func (tr MySqlTwoFactorRepository) UseStep(accountID string, step int64) (bool, error) {
+	query := sq.Update("account_two_factor").
+		Set("last_used_step", step).
+		Where(sq.Eq{"account_id": accountID}).
+		Where(sq.Lt{"last_used_step": step})
+
+	return tr.affectOne("UTS", query)
+}
+
+func (tr MySqlTwoFactorRepository) UseRecoveryCode(accountID, codeHash string) (bool, error) {
+	query := sq.Delete("account_recovery_code").
+		Where(sq.Eq{
+			"account_id": accountID,
+			"code_hash":  codeHash,
+		})
+
+	return tr.affectOne("URC", query)
+}
+
+// affectOne executes the query and reports whether it changed a row
+func (tr MySqlTwoFactorRepository) affectOne(tag string, query sq.Sqlizer) (bool, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag(tag+"00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	result, err := tr.Db.Exec(sql, args...)
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag(tag+"01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag(tag+"02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return affected > 0, nil
+}
@@ -7,6 +7,8 @@ const (
 	TYPE_NOT_FOUND    = 1
 	TYPE_UNAUTHORIZED = 2
 	TYPE_EXPIRED      = 3
+	TYPE_BAD_REQUEST  = 4
+	TYPE_CONFLICT     = 5
 )
 
 type Error struct {
@@ -1,22 +1,28 @@
 package delivery
 
 import (
-	"database/sql"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator"
+	validator "github.com/go-playground/validator/v10"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
 	"github.com/pajri/personal-backend/domain"
 	"github.com/pajri/personal-backend/global"
 	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
 )
 
+const OIDC_STATE_COOKIE = "oidc_state"
+
 // #region type helper
 type LoginRequest struct {
 	Email    string `form:"email" binding:"required"`
@@ -24,14 +30,140 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Message     []string `json:"message"`
-	AccessToken string   `json:"access_token"`
+	Message           []string `json:"message"`
+	AccessToken       string   `json:"access_token"`
+	TwoFactorRequired bool     `json:"two_factor_required,omitempty"`
+	ChallengeToken    string   `json:"challenge_token,omitempty"`
+}
+
+type LockedResponse struct {
+	Message []string `json:"message"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type SetupTwoFactorResponse struct {
+	Message         string `json:"message"`
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type EnableTwoFactorResponse struct {
+	Message       []string `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorResponse struct {
+	Message []string `json:"message"`
+}
+
+type PasskeyRegistrationResponse struct {
+	Message string                         `json:"message"`
+	Options *domain.PasskeyCreationOptions `json:"options,omitempty"`
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	Name              string   `json:"name" binding:"max=100"`
+	ClientDataJSON    string   `json:"client_data_json" binding:"required"`
+	AttestationObject string   `json:"attestation_object" binding:"required"`
+	Transports        []string `json:"transports"`
+}
+
+type PasskeyLoginResponse struct {
+	Message string                        `json:"message"`
+	Options *domain.PasskeyRequestOptions `json:"options,omitempty"`
+}
+
+type FinishPasskeyLoginRequest struct {
+	CredentialID      string `json:"credential_id" binding:"required"`
+	ClientDataJSON    string `json:"client_data_json" binding:"required"`
+	AuthenticatorData string `json:"authenticator_data" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"user_handle"`
+}
+
+type PasskeyRequest struct {
+	CredentialID string `json:"credential_id" binding:"required"`
+	Name         string `json:"name" binding:"max=100"`
+}
+
+type PasskeyResponse struct {
+	Message []string        `json:"message"`
+	Passkey *PasskeyElement `json:"passkey,omitempty"`
+}
+
+type PasskeyListResponse struct {
+	Message     string           `json:"message"`
+	PasskeyList []PasskeyElement `json:"passkey_list"`
+}
+
+type PasskeyElement struct {
+	CredentialID string `json:"credential_id"`
+	Name         string `json:"name"`
+	CreatedAt    string `json:"created_at"`
+	LastUsedAt   string `json:"last_used_at,omitempty"`
+}
+
+type BeginOIDCLoginRequest struct {
+	Provider string `json:"provider" binding:"required"`
+}
+
+type BeginOIDCLoginResponse struct {
+	Message          string `json:"message"`
+	AuthorizationURL string `json:"authorization_url,omitempty"`
+}
+
+type FinishOIDCLoginRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkResponse struct {
+	Message []string `json:"message"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type SessionListResponse struct {
+	Message     string           `json:"message"`
+	SessionList []SessionElement `json:"session_list"`
+}
+
+type SessionElement struct {
+	SessionID  string `json:"session_id"`
+	DeviceName string `json:"device_name"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	IsCurrent  bool   `json:"is_current"`
+}
+
+type SessionResponse struct {
+	Message []string `json:"message"`
 }
 
 type SignUpRequest struct {
 	Fullname        string `form:"full_name" binding:"required"`
 	Email           string `form:"email" binding:"required"`
-	Password        string `form:"password" binding:"required,min=10"`
+	Password        string `form:"password" binding:"required"`
 	ConfirmPassword string `form:"confirm_password" binding:"required,eqfield=Password"`
 }
 
@@ -92,6 +224,25 @@ func NewAuthHandler(router *gin.Engine, authUsecase domain.IAuthUsecase) {
 	router.POST("/api/auth/reset_password/", handler.ResetPassword)
 	router.POST("/api/auth/change_password", handler.ChangePassword)
 	router.POST("/api/auth/signout", handler.SignOut)
+	router.POST("/api/auth/2fa/verify", handler.VerifyTwoFactor)
+	router.POST("/api/auth/2fa/setup", handler.SetupTwoFactor)
+	router.POST("/api/auth/2fa/enable", handler.EnableTwoFactor)
+	router.POST("/api/auth/2fa/disable", handler.DisableTwoFactor)
+	router.POST("/api/auth/passkey/register/begin", handler.BeginPasskeyRegistration)
+	router.POST("/api/auth/passkey/register/finish", handler.FinishPasskeyRegistration)
+	router.POST("/api/auth/passkey/login/begin", handler.BeginPasskeyLogin)
+	router.POST("/api/auth/passkey/login/finish", handler.FinishPasskeyLogin)
+	router.GET("/api/auth/passkey", handler.PasskeyList)
+	router.POST("/api/auth/passkey/rename", handler.RenamePasskey)
+	router.POST("/api/auth/passkey/revoke", handler.RevokePasskey)
+	router.POST("/api/auth/oidc/begin", handler.BeginOIDCLogin)
+	router.POST("/api/auth/oidc/callback", handler.FinishOIDCLogin)
+	router.POST("/api/auth/magic_link", handler.SendMagicLink)
+	router.POST("/api/auth/magic_link/login", handler.LoginWithMagicLink)
+	router.GET("/api/auth/sessions", handler.SessionList)
+	router.DELETE("/api/auth/sessions", handler.RevokeAllSessions)
+	router.DELETE("/api/auth/sessions/:id", handler.RevokeSession)
+	router.GET("/.well-known/jwks.json", handler.JWKS)
 }
 
 func (ah AuthHandler) Login(c *gin.Context) {
@@ -105,8 +256,8 @@ func (ah AuthHandler) Login(c *gin.Context) {
 		cerr := cerror.NewAndPrintWithTag("ALG", err, global.FRIENDLY_MESSAGE)
 
 		/*start validation*/
-		valError := err.(validator.ValidationErrors)
-		if valError != nil {
+		valError, ok := err.(validator.ValidationErrors)
+		if ok {
 			for _, elem := range valError {
 				fieldName := elem.Field()
 				field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
@@ -138,21 +289,59 @@ func (ah AuthHandler) Login(c *gin.Context) {
 	account.Email = request.Email
 	account.Password = request.Password
 
-	token, err := ah.useCase.Login(account)
+	token, challengeToken, err := ah.useCase.Login(account, ah.clientInfo(c))
+	if ah.handleLocked(c, err) {
+		return
+	}
+
 	if err != nil {
-		response := LoginResponse{
-			Message: []string{err.(cerror.Error).FriendlyMessageWithTag()},
-		}
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
 
-		httpStatus := http.StatusInternalServerError
-		if err.(cerror.Error).Err == sql.ErrNoRows {
-			httpStatus = http.StatusNotFound
-			response.Message = []string{global.FRIENDLY_INVALID_USNME_PASSWORD}
-		} else if err.(cerror.Error).Type == cerror.TYPE_UNAUTHORIZED {
-			httpStatus = http.StatusUnauthorized
-		}
+	//the client has to send a code to /api/auth/2fa/verify to get the tokens
+	if challengeToken != "" {
+		response.TwoFactorRequired = true
+		response.ChallengeToken = challengeToken
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response.AccessToken = token.AccessToken
+
+	cookieHelper := helper.CookieHelper{}
+	cookie := cookieHelper.SetHttpOnlyCookie("refresh_token", token.RefreshToken, token.RefreshTokenExpTime)
+	http.SetCookie(c.Writer, cookie)
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var (
+		request  VerifyTwoFactorRequest
+		response LoginResponse
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("VTH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	token, err := ah.useCase.VerifyTwoFactor(request.ChallengeToken, request.Code, ah.clientInfo(c))
+	if ah.handleLocked(c, err) {
+		return
+	}
 
-		c.JSON(httpStatus, response)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
 		return
 	}
 
@@ -166,6 +355,84 @@ func (ah AuthHandler) Login(c *gin.Context) {
 	return
 }
 
+func (ah AuthHandler) SetupTwoFactor(c *gin.Context) {
+	var (
+		response  SetupTwoFactorResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	setup, err := ah.useCase.SetupTwoFactor(accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	response.Secret = setup.Secret
+	response.ProvisioningURI = setup.ProvisioningURI
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) EnableTwoFactor(c *gin.Context) {
+	var (
+		request   EnableTwoFactorRequest
+		response  EnableTwoFactorResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("ETH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	recoveryCodes, err := ah.useCase.EnableTwoFactor(accountID, request.Code)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	response.RecoveryCodes = recoveryCodes
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var (
+		request   DisableTwoFactorRequest
+		response  DisableTwoFactorResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("DTH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ah.useCase.DisableTwoFactor(accountID, request.Password, request.Code)
+	if ah.handleLocked(c, err) {
+		return
+	}
+
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
 func (ah AuthHandler) SignUp(c *gin.Context) {
 	var (
 		request  SignUpRequest
@@ -177,8 +444,8 @@ func (ah AuthHandler) SignUp(c *gin.Context) {
 		cerr := cerror.NewAndPrintWithTag("ASU00", err, global.FRIENDLY_MESSAGE)
 
 		/*start validation*/
-		valError := err.(validator.ValidationErrors)
-		if valError != nil {
+		valError, ok := err.(validator.ValidationErrors)
+		if ok {
 			for _, elem := range valError {
 				fieldName := elem.Field()
 				field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
@@ -194,11 +461,6 @@ func (ah AuthHandler) SignUp(c *gin.Context) {
 					msg := fmt.Sprintf(global.ERR_DIFFERENT_FORMATTER, jsonField, "password")
 					response.Message = append(response.Message, msg)
 					break
-
-				case "min":
-					msg := fmt.Sprintf(global.ERR_MIN_CHAR, jsonField, elem.Param())
-					response.Message = append(response.Message, msg)
-					break
 				}
 
 			}
@@ -227,6 +489,12 @@ func (ah AuthHandler) SignUp(c *gin.Context) {
 
 	//create account
 	createdAccount, createdProfile, err := ah.useCase.SignUp(account, profile)
+	if perr, ok := err.(helper.PasswordPolicyError); ok {
+		response.Message = perr.Violations
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err != nil {
 		err.(cerror.Error).PrintErrorWithTag()
 		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
@@ -261,7 +529,7 @@ func (ah AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	refreshToken := rtCookie.Value
-	token, err := ah.useCase.RefreshToken(refreshToken)
+	token, err := ah.useCase.RefreshToken(refreshToken, ah.clientInfo(c))
 	if err != nil {
 		//handle token expired
 		cerr, ok := err.(cerror.Error)
@@ -338,8 +606,8 @@ func (ah AuthHandler) ResetPassword(c *gin.Context) {
 		cerr := cerror.NewAndPrintWithTag("RPH00", err, global.FRIENDLY_MESSAGE)
 
 		/*start validation*/
-		valError := err.(validator.ValidationErrors)
-		if valError != nil {
+		valError, ok := err.(validator.ValidationErrors)
+		if ok {
 			for _, elem := range valError {
 				fieldName := elem.Field()
 				field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
@@ -398,8 +666,8 @@ func (ah AuthHandler) ChangePassword(c *gin.Context) {
 		cerr := cerror.NewAndPrintWithTag("CPH00", err, global.FRIENDLY_MESSAGE)
 
 		/*start form validation*/
-		valError := err.(validator.ValidationErrors)
-		if valError != nil {
+		valError, ok := err.(validator.ValidationErrors)
+		if ok {
 			for _, elem := range valError {
 				fieldName := elem.Field()
 				field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
@@ -432,6 +700,12 @@ func (ah AuthHandler) ChangePassword(c *gin.Context) {
 	if len(query) > 0 && query["token"] != nil && len(query["token"]) > 0 { //token validation
 		resetPasswordToken = query["token"][0]
 		err := ah.useCase.ChangePassword(resetPasswordToken, request.Password)
+		if perr, ok := err.(helper.PasswordPolicyError); ok {
+			response.Message = perr.Violations
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
 		if err != nil {
 			cerr, ok := err.(cerror.Error)
 			if ok {
@@ -467,7 +741,7 @@ func (ah AuthHandler) SignOut(c *gin.Context) {
 	if len(authArr) > 0 {
 		accessTokenString := authArr[0] //get access token from header
 
-		accessToken, err = jwtHelper.ParseToken(accessTokenString) //parse token component into struct
+		accessToken, err = jwtHelper.ParseToken(accessTokenString, helper.TOKEN_USE_ACCESS) //parse token component into struct
 		if err != nil {
 			cerr := cerror.NewAndPrintWithTag("SOA00", err, global.FRIENDLY_INVALID_TOKEN)
 			response.ErrprType = "token_invalid"
@@ -487,7 +761,7 @@ func (ah AuthHandler) SignOut(c *gin.Context) {
 		return
 	}
 
-	refreshToken, err = jwtHelper.ParseToken(rtCookie.Value) //parse token component into struct
+	refreshToken, err = jwtHelper.ParseToken(rtCookie.Value, helper.TOKEN_USE_REFRESH) //parse token component into struct
 	if err != nil {
 		response.ErrprType = "token_invalid"
 		cerr := cerror.NewAndPrintWithTag("SOA02", err, global.FRIENDLY_INVALID_TOKEN)
@@ -510,3 +784,491 @@ func (ah AuthHandler) SignOut(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 	return
 }
+
+func (ah AuthHandler) BeginPasskeyRegistration(c *gin.Context) {
+	var (
+		response  PasskeyRegistrationResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	options, err := ah.useCase.BeginPasskeyRegistration(accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	response.Options = options
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) FinishPasskeyRegistration(c *gin.Context) {
+	var (
+		request      FinishPasskeyRegistrationRequest
+		response     PasskeyResponse
+		registration domain.PasskeyRegistration
+		accountID    string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err == nil {
+		registration.ClientDataJSON, err = helper.DecodeWebAuthnID(request.ClientDataJSON)
+	}
+	if err == nil {
+		registration.AttestationObject, err = helper.DecodeWebAuthnID(request.AttestationObject)
+	}
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("PRH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+	registration.Name = p.Sanitize(request.Name)
+	registration.Transports = request.Transports
+
+	passkey, err := ah.useCase.FinishPasskeyRegistration(accountID, registration)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	element := ah.createPasskeyElement(*passkey)
+	response.Passkey = &element
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (ah AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	var response PasskeyLoginResponse
+
+	options, err := ah.useCase.BeginPasskeyLogin()
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	response.Options = options
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) FinishPasskeyLogin(c *gin.Context) {
+	var (
+		request   FinishPasskeyLoginRequest
+		response  LoginResponse
+		assertion domain.PasskeyAssertion
+	)
+
+	err := c.ShouldBind(&request)
+	if err == nil {
+		assertion.CredentialID, err = helper.DecodeWebAuthnID(request.CredentialID)
+	}
+	if err == nil {
+		assertion.ClientDataJSON, err = helper.DecodeWebAuthnID(request.ClientDataJSON)
+	}
+	if err == nil {
+		assertion.AuthenticatorData, err = helper.DecodeWebAuthnID(request.AuthenticatorData)
+	}
+	if err == nil {
+		assertion.Signature, err = helper.DecodeWebAuthnID(request.Signature)
+	}
+	if err == nil {
+		assertion.UserHandle, err = helper.DecodeWebAuthnID(request.UserHandle)
+	}
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FLP00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	token, err := ah.useCase.FinishPasskeyLogin(assertion, ah.clientInfo(c))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	response.AccessToken = token.AccessToken
+
+	cookieHelper := helper.CookieHelper{}
+	cookie := cookieHelper.SetHttpOnlyCookie("refresh_token", token.RefreshToken, token.RefreshTokenExpTime)
+	http.SetCookie(c.Writer, cookie)
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) PasskeyList(c *gin.Context) {
+	var (
+		response  PasskeyListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	passkeyList, err := ah.useCase.PasskeyList(accountID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.PasskeyList = []PasskeyElement{}
+	for _, passkey := range passkeyList {
+		response.PasskeyList = append(response.PasskeyList, ah.createPasskeyElement(passkey))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) RenamePasskey(c *gin.Context) {
+	ah.handlePasskeyAction(c, "RPK00", func(credentialID []byte, accountID, name string) error {
+		return ah.useCase.RenamePasskey(credentialID, accountID, name)
+	})
+}
+
+func (ah AuthHandler) RevokePasskey(c *gin.Context) {
+	ah.handlePasskeyAction(c, "VPK00", func(credentialID []byte, accountID, name string) error {
+		return ah.useCase.RevokePasskey(credentialID, accountID)
+	})
+}
+
+func (ah AuthHandler) handlePasskeyAction(c *gin.Context, tag string, action func(credentialID []byte, accountID, name string) error) {
+	var (
+		request      PasskeyRequest
+		response     PasskeyResponse
+		credentialID []byte
+		accountID    string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err == nil {
+		credentialID, err = helper.DecodeWebAuthnID(request.CredentialID)
+	}
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+	err = action(credentialID, accountID, p.Sanitize(request.Name))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ah AuthHandler) BeginOIDCLogin(c *gin.Context) {
+	var (
+		request  BeginOIDCLoginRequest
+		response BeginOIDCLoginResponse
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("BOH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	authorizationURL, state, err := ah.useCase.BeginOIDCLogin(request.Provider)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	//binds the state to this browser, the state itself expires in redis
+	http.SetCookie(c.Writer, ah.oidcStateCookie(state))
+
+	response.AuthorizationURL = authorizationURL
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+// FinishOIDCLogin receives the code and state the provider redirected the
+// frontend with
+func (ah AuthHandler) FinishOIDCLogin(c *gin.Context) {
+	var (
+		request  FinishOIDCLoginRequest
+		response LoginResponse
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FOH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var browserState string
+	stateCookie, err := c.Request.Cookie(OIDC_STATE_COOKIE)
+	if err == nil {
+		browserState = stateCookie.Value
+	}
+	http.SetCookie(c.Writer, ah.oidcStateCookie(""))
+
+	token, challengeToken, err := ah.useCase.FinishOIDCLogin(request.Code, request.State, browserState, ah.clientInfo(c))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	if challengeToken != "" {
+		response.TwoFactorRequired = true
+		response.ChallengeToken = challengeToken
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response.AccessToken = token.AccessToken
+
+	cookieHelper := helper.CookieHelper{}
+	cookie := cookieHelper.SetHttpOnlyCookie("refresh_token", token.RefreshToken, token.RefreshTokenExpTime)
+	http.SetCookie(c.Writer, cookie)
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+// SendMagicLink responds the same way whether the email is registered or not
+func (ah AuthHandler) SendMagicLink(c *gin.Context) {
+	var (
+		request  MagicLinkRequest
+		response MagicLinkResponse
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("SMH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+	request.Email = p.Sanitize(request.Email)
+
+	//any other error is printed by the usecase, answering it would tell
+	//registered emails apart
+	err = ah.useCase.SendMagicLink(request.Email, ah.clientInfo(c).IPAddress)
+	if ah.handleLocked(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) LoginWithMagicLink(c *gin.Context) {
+	var (
+		request  MagicLinkLoginRequest
+		response LoginResponse
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("LMH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	token, challengeToken, err := ah.useCase.LoginWithMagicLink(request.Token, ah.clientInfo(c))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	if challengeToken != "" {
+		response.TwoFactorRequired = true
+		response.ChallengeToken = challengeToken
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response.AccessToken = token.AccessToken
+
+	cookieHelper := helper.CookieHelper{}
+	cookie := cookieHelper.SetHttpOnlyCookie("refresh_token", token.RefreshToken, token.RefreshTokenExpTime)
+	http.SetCookie(c.Writer, cookie)
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) SessionList(c *gin.Context) {
+	var (
+		response  SessionListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	sessionList, err := ah.useCase.SessionList(accountID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.SessionList = []SessionElement{}
+	for _, session := range sessionList {
+		response.SessionList = append(response.SessionList, ah.createSessionElement(c, session))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ah AuthHandler) RevokeSession(c *gin.Context) {
+	var (
+		response  SessionResponse
+		sessionID string = c.Param("id")
+		accountID string = c.GetString("account_id")
+	)
+
+	err := ah.useCase.RevokeSession(sessionID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	if sessionID == c.GetString("session_id") {
+		cookieHelper := helper.CookieHelper{}
+		http.SetCookie(c.Writer, cookieHelper.RemoveHttpOnlyCookie("refresh_token"))
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+	return
+}
+
+// RevokeAllSessions signs out everywhere, including the current device
+func (ah AuthHandler) RevokeAllSessions(c *gin.Context) {
+	var (
+		response  SessionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := ah.useCase.RevokeAllSessions(accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ah.errorStatus(cerr), response)
+		return
+	}
+
+	cookieHelper := helper.CookieHelper{}
+	http.SetCookie(c.Writer, cookieHelper.RemoveHttpOnlyCookie("refresh_token"))
+
+	c.JSON(http.StatusNoContent, nil)
+	return
+}
+
+// JWKS publishes the keys tokens are verified with, other services cache
+// it and refetch when they see an unknown kid
+func (ah AuthHandler) JWKS(c *gin.Context) {
+	jwtHelper := helper.JWTHelper{}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwtHelper.JWKS())
+}
+
+func (ah AuthHandler) createSessionElement(c *gin.Context, session domain.Session) SessionElement {
+	var element SessionElement
+	element.SessionID = session.SessionID
+	element.DeviceName = session.DeviceName
+	element.IPAddress = session.IPAddress
+	element.CreatedAt = session.CreatedAt.Format(global.TIME_ISO8601)
+	element.LastUsedAt = session.LastUsedAt.Format(global.TIME_ISO8601)
+	element.IsCurrent = session.SessionID == c.GetString("session_id")
+
+	return element
+}
+
+// oidcStateCookie removes the cookie when state is empty, it is only sent
+// to the oidc routes and not on cross site requests
+func (ah AuthHandler) oidcStateCookie(state string) *http.Cookie {
+	cookieHelper := helper.CookieHelper{}
+
+	var cookie *http.Cookie
+	if state == "" {
+		cookie = cookieHelper.RemoveHttpOnlyCookie(OIDC_STATE_COOKIE)
+	} else {
+		cookie = cookieHelper.SetHttpOnlyCookie(OIDC_STATE_COOKIE, state, time.Time{})
+	}
+	cookie.Path = "/api/auth/oidc"
+	cookie.SameSite = http.SameSiteLaxMode
+
+	return cookie
+}
+
+// clientInfo does not use c.ClientIP, it trusts forwarding headers from
+// anyone and the login lockout counts failures per ip address
+func (ah AuthHandler) clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{
+		IPAddress: util.ClientIP(c.Request, config.Config.TrustedProxies),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func (ah AuthHandler) createPasskeyElement(passkey domain.Passkey) PasskeyElement {
+	var element PasskeyElement
+	element.CredentialID = helper.EncodeWebAuthnID(passkey.CredentialID)
+	element.Name = passkey.Name
+	element.CreatedAt = passkey.CreatedAt.Format(global.TIME_ISO8601)
+	if passkey.LastUsedAt != nil {
+		element.LastUsedAt = passkey.LastUsedAt.Format(global.TIME_ISO8601)
+	}
+
+	return element
+}
+
+// handleLocked responds 429 with Retry-After when err is a
+// LoginLockedError
+func (ah AuthHandler) handleLocked(c *gin.Context, err error) bool {
+	lerr, ok := err.(domain.LoginLockedError)
+	if !ok {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(lerr.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, LockedResponse{Message: []string{global.FRIENDLY_LOGIN_LOCKED}})
+	return true
+}
+
+func (ah AuthHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case cerror.TYPE_UNAUTHORIZED, cerror.TYPE_EXPIRED:
+		return http.StatusUnauthorized
+	case cerror.TYPE_CONFLICT:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base32"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -15,72 +19,159 @@ import (
 	"github.com/pajri/personal-backend/domain"
 	"github.com/pajri/personal-backend/global"
 	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const SALT_BYTES = 32
+const (
+	SALT_BYTES = 32
+
+	TWO_FACTOR_CHALLENGE_PREFIX   = "2fa_challenge:"
+	TWO_FACTOR_CHALLENGE_EXPIRY   = 5 * time.Minute
+	TWO_FACTOR_CHALLENGE_ATTEMPTS = 5
+	RECOVERY_CODE_COUNT           = 10
+	RECOVERY_CODE_BYTES           = 10
+)
 
 type AuthUsecase struct {
-	accountRepo domain.IAccountRepository
-	profileRepo domain.IProfileRepository
-	mailHelper  helper.IEMail
+	accountRepo    domain.IAccountRepository
+	profileRepo    domain.IProfileRepository
+	twoFactorRepo  domain.ITwoFactorRepository
+	passkeyRepo    domain.IPasskeyRepository
+	identityRepo   domain.IAccountIdentityRepository
+	sessionRepo    domain.ISessionRepository
+	mailHelper     helper.IEMail
+	webAuthnHelper helper.IWebAuthn
+	oidcHelper     helper.IOIDC
+	passwordPolicy helper.IPasswordPolicy
 }
 
 func NewAuthUsecase(accountRepository domain.IAccountRepository,
 	profileRepository domain.IProfileRepository,
-	_mailHelper helper.IEMail) domain.IAuthUsecase {
+	twoFactorRepository domain.ITwoFactorRepository,
+	passkeyRepository domain.IPasskeyRepository,
+	identityRepository domain.IAccountIdentityRepository,
+	sessionRepository domain.ISessionRepository,
+	_mailHelper helper.IEMail,
+	_webAuthnHelper helper.IWebAuthn,
+	_oidcHelper helper.IOIDC,
+	_passwordPolicy helper.IPasswordPolicy) domain.IAuthUsecase {
 	return &AuthUsecase{
-		accountRepo: accountRepository,
-		profileRepo: profileRepository,
-		mailHelper:  _mailHelper,
+		accountRepo:    accountRepository,
+		profileRepo:    profileRepository,
+		twoFactorRepo:  twoFactorRepository,
+		passkeyRepo:    passkeyRepository,
+		identityRepo:   identityRepository,
+		sessionRepo:    sessionRepository,
+		mailHelper:     _mailHelper,
+		webAuthnHelper: _webAuthnHelper,
+		oidcHelper:     _oidcHelper,
+		passwordPolicy: _passwordPolicy,
 	}
 }
 
-func (uc AuthUsecase) Login(account domain.Account) (*helper.JWTWrapper, error) {
+func (uc AuthUsecase) Login(account domain.Account, client domain.ClientInfo) (*helper.JWTWrapper, string, error) {
+	err := uc.checkLoginLock(account.Email, client.IPAddress)
+	if err != nil {
+		return nil, "", err
+	}
+
+	//an unknown email gets the same response as a wrong password
 	filter := domain.AccountFilter{Email: account.Email}
 	regAccount, err := uc.accountRepo.GetAccount(filter)
 	if err != nil {
-		return nil, err
+		cerr, ok := err.(cerror.Error)
+		if !ok || cerr.Err != sql.ErrNoRows {
+			return nil, "", err
+		}
+
+		uc.compareDummyPassword(account.Password)
+		return nil, "", uc.recordLoginFailure(account.Email, client.IPAddress, nil)
 	}
 
 	err, ok := uc.comparePassword([]byte(account.Password), regAccount.Salt, []byte(regAccount.Password))
 	if !ok || err != nil {
-		cerr := cerror.NewAndPrintWithTag("LGU03",
-			errors.New("incorrect password for email :"+account.Email),
-			global.FRIENDLY_INVALID_USNME_PASSWORD)
-		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, "", uc.recordLoginFailure(account.Email, client.IPAddress, regAccount)
+	}
+
+	uc.resetLoginFailures(account.Email)
 
+	if !regAccount.IsVerified {
+		err := fmt.Errorf("email %s has not been verified", regAccount.Email)
+		cerr := cerror.NewAndPrintWithTag("LGU04", err, global.FRIENDLY_EMAIL_NOT_VERIFIED)
+		return nil, "", cerr
+	}
+
+	if uc.passwordNeedsRehash(regAccount.Password) {
+		uc.rehashPassword(*regAccount, account.Password)
+	}
+
+	return uc.completeLogin(regAccount.AccountID, client)
+}
+
+// VerifyTwoFactor completes a login with a totp code or a recovery code,
+// the challenge is dropped after too many wrong codes and the account is
+// locked after too many wrong codes across challenges
+func (uc AuthUsecase) VerifyTwoFactor(challengeToken, code string, client domain.ClientInfo) (*helper.JWTWrapper, error) {
+	challengeKey := TWO_FACTOR_CHALLENGE_PREFIX + util.HashToken(challengeToken)
+	accountID, _ := helper.RedisHelper.Get(challengeKey)
+	if accountID == "" {
+		cerr := cerror.NewAndPrintWithTag("VTF00", errors.New("two-factor challenge is not found"), global.FRIENDLY_TWO_FACTOR_CHALLENGE)
+		cerr.Type = cerror.TYPE_EXPIRED
 		return nil, cerr
 	}
 
-	if regAccount != nil {
-		if !regAccount.IsVerified {
-			err := fmt.Errorf("email %s has not been verified", regAccount.Email)
-			cerr := cerror.NewAndPrintWithTag("LGU04", err, global.FRIENDLY_EMAIL_NOT_VERIFIED)
-			return nil, cerr
-		}
+	err := uc.checkThrottleLock(uc.twoFactorThrottle(accountID))
+	if err != nil {
+		return nil, err
+	}
 
-		filterProfile := domain.ProfileFilter{AccountID: regAccount.AccountID}
-		regProfile, err := uc.profileRepo.GetProfile(filterProfile)
-		if err != nil {
-			return nil, err
-		}
+	exp := time.Now().Add(TWO_FACTOR_CHALLENGE_EXPIRY).Unix()
+	attempts, err := helper.RedisHelper.Increment(challengeKey+":attempts", exp)
+	if err != nil {
+		return nil, err
+	}
 
-		token, err := uc.createTokenPair(*regAccount, *regProfile)
-		if err != nil {
-			return nil, err
-		}
+	if attempts > TWO_FACTOR_CHALLENGE_ATTEMPTS {
+		_ = helper.RedisHelper.Delete(challengeKey)
+		errMsg := fmt.Sprintf("too many two-factor attempts for account %s", accountID)
+		cerr := cerror.NewAndPrintWithTag("VTF01", errors.New(errMsg), global.FRIENDLY_TWO_FACTOR_CHALLENGE)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, cerr
+	}
+
+	twoFactor, err := uc.getTwoFactor(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil || !twoFactor.IsEnabled {
+		cerr := cerror.NewAndPrintWithTag("VTF02", errors.New("two-factor is disabled during challenge"), global.FRIENDLY_TWO_FACTOR_CHALLENGE)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, cerr
+	}
+
+	err = uc.verifyTwoFactorCode(*twoFactor, code)
+	if err != nil {
+		return nil, uc.twoFactorFailure(accountID, client.IPAddress, err)
+	}
+	uc.resetTwoFactorFailures(accountID)
 
-		return token, nil
+	err = helper.RedisHelper.Delete(challengeKey)
+	if err != nil {
+		return nil, err
 	}
 
-	userNilErr := cerror.NewAndPrintWithTag("LGU02", errors.New("user nil"), global.FRIENDLY_INVALID_USNME_PASSWORD)
-	return nil, userNilErr
+	return uc.issueToken(accountID, client)
 }
 
 func (uc AuthUsecase) SignUp(account domain.Account, profile domain.Profile) (*domain.Account, *domain.Profile, error) {
+	err := uc.passwordPolicy.Validate(account.Password, account.Email, profile.FullName)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	//create salt
-	var err error
 	account.Salt, err = uc.generateSalt()
 	if err != nil {
 		return nil, nil, err
@@ -97,7 +188,7 @@ func (uc AuthUsecase) SignUp(account domain.Account, profile domain.Profile) (*d
 	emailTokenClaims["exp"] = time.Now().Add(15 * time.Minute).Unix()
 
 	jwtHelper := helper.JWTHelper{}
-	account.EmailToken, err = jwtHelper.CreateToken(emailTokenClaims)
+	account.EmailToken, err = jwtHelper.CreateToken(helper.TOKEN_USE_EMAIL_VERIFICATION, emailTokenClaims)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -129,9 +220,13 @@ func (uc AuthUsecase) SignUp(account domain.Account, profile domain.Profile) (*d
 	return insertedAccount, &profile, nil
 }
 
-func (uc AuthUsecase) RefreshToken(refreshToken string) (*helper.JWTWrapper, error) {
+// RefreshToken rotates the refresh token of a session, each session is a
+// token family that only accepts its latest refresh token. Presenting an
+// older one revokes the session, tokens issued before sessions were
+// recorded have to log in again
+func (uc AuthUsecase) RefreshToken(refreshToken string, client domain.ClientInfo) (*helper.JWTWrapper, error) {
 	jwtHelper := helper.JWTHelper{}
-	token, err := jwtHelper.ParseToken(refreshToken)
+	token, err := jwtHelper.ParseToken(refreshToken, helper.TOKEN_USE_REFRESH)
 	if err != nil {
 		//including expiration error
 		//so, no need further check for token expiration
@@ -140,11 +235,41 @@ func (uc AuthUsecase) RefreshToken(refreshToken string) (*helper.JWTWrapper, err
 	}
 	mapClaims := token.Claims.(jwt.MapClaims)
 
-	//validate token in redis
-	rtRedis, _ := helper.RedisHelper.Get(mapClaims["refresh_uuid"].(string))
+	//validate session
+	sessionID, _ := mapClaims["session_id"].(string)
+	session, err := uc.getSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil {
+		//session is revoked
+		cerr := cerror.NewAndPrintWithTag("RTU01", errors.New("session is not found"), global.FRIENDLY_TOKEN_EXPIRED)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, cerr
+	}
+
+	refreshUUID := mapClaims["refresh_uuid"].(string)
+	if refreshUUID != session.RefreshUUID {
+		return nil, uc.refreshTokenReused(*session)
+	}
+
+	//validate token in redis, popping it makes the token single use so a
+	//concurrent refresh with the same token also counts as reuse. Pop runs
+	//on its own pooled connection, so an empty value means the token was
+	//really consumed, a redis error is returned without revoking
+	rtRedis, err := helper.RedisHelper.Pop(refreshUUID)
+	if err != nil {
+		return nil, err
+	}
+
 	if rtRedis == "" {
-		//token is expired
-		cerr := cerror.NewAndPrintWithTag("RTU00", errors.New("token_expired"), global.FRIENDLY_TOKEN_EXPIRED)
+		return nil, uc.refreshTokenReused(*session)
+	}
+
+	//the key holds the token it was issued for
+	if subtle.ConstantTimeCompare([]byte(rtRedis), []byte(refreshToken)) != 1 {
+		cerr := cerror.NewAndPrintWithTag("RTU03", errors.New("refresh token does not match the stored token"), global.FRIENDLY_TOKEN_EXPIRED)
 		cerr.Type = cerror.TYPE_EXPIRED
 		return nil, cerr
 	}
@@ -164,16 +289,33 @@ func (uc AuthUsecase) RefreshToken(refreshToken string) (*helper.JWTWrapper, err
 	}
 
 	//create token
-	tokenPair, err := uc.createTokenPair(*account, *profile)
+	oldAccessUUID := session.AccessUUID
+	tokenPair, err := uc.createTokenPair(*account, *profile, session)
+	if err != nil {
+		return nil, err
+	}
+
+	session.IPAddress = client.IPAddress
+	session.UserAgent = client.UserAgent
+	session.LastUsedAt = time.Now()
+	err = uc.sessionRepo.UpdateSession(*session)
 	if err != nil {
 		return nil, err
 	}
+
+	//the session only tracks its latest access token, so the previous one
+	//is dropped to keep revocation complete
+	err = helper.RedisHelper.Delete(oldAccessUUID)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("RTU02", err, global.FRIENDLY_MESSAGE)
+	}
+
 	return tokenPair, nil
 }
 
 func (uc AuthUsecase) VerifyEmail(token string) error {
 	jwtHelper := helper.JWTHelper{}
-	parsedToken, err := jwtHelper.ParseToken(token)
+	parsedToken, err := jwtHelper.ParseToken(token, helper.TOKEN_USE_EMAIL_VERIFICATION)
 	if err != nil {
 		return cerror.NewAndPrintWithTag("VEA00", err, global.FRIENDLY_INVALID_TOKEN)
 	}
@@ -213,7 +355,7 @@ func (uc AuthUsecase) ResetPassword(email string) error {
 	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
 
 	jwtHelper := helper.JWTHelper{}
-	token, err := jwtHelper.CreateToken(claims)
+	token, err := jwtHelper.CreateToken(helper.TOKEN_USE_RESET_PASSWORD, claims)
 	if err != nil {
 		return err
 	}
@@ -255,7 +397,7 @@ func (uc AuthUsecase) ResetPassword(email string) error {
 
 func (uc AuthUsecase) ChangePassword(token, password string) error {
 	jwtHelper := helper.JWTHelper{}
-	parsedToken, err := jwtHelper.ParseToken(token)
+	parsedToken, err := jwtHelper.ParseToken(token, helper.TOKEN_USE_RESET_PASSWORD)
 	if err != nil {
 		return cerror.NewAndPrintWithTag("CPW00", err, global.FRIENDLY_INVALID_TOKEN)
 	}
@@ -288,6 +430,16 @@ func (uc AuthUsecase) ChangePassword(token, password string) error {
 		return cerr
 	}
 
+	profile, err := uc.profileRepo.GetProfile(domain.ProfileFilter{AccountID: account.AccountID})
+	if err != nil {
+		return err
+	}
+
+	err = uc.passwordPolicy.Validate(password, account.Email, profile.FullName, profile.Username)
+	if err != nil {
+		return err
+	}
+
 	account.Salt, err = uc.generateSalt()
 	if err != nil {
 		return err
@@ -311,7 +463,8 @@ func (uc AuthUsecase) ChangePassword(token, password string) error {
 		return err
 	}
 
-	return nil
+	//whoever knew the old password must not stay signed in
+	return uc.RevokeAllSessions(account.AccountID)
 }
 
 func (uc AuthUsecase) SignOut(accessToken, refreshToken *jwt.Token) error {
@@ -329,7 +482,136 @@ func (uc AuthUsecase) SignOut(accessToken, refreshToken *jwt.Token) error {
 		return cerror.NewAndPrintWithTag("SOU01", err, global.FRIENDLY_MESSAGE)
 	}
 
-	return nil
+	sessionID, _ := rtClaims["session_id"].(string)
+	if sessionID == "" {
+		return nil
+	}
+
+	return uc.deleteSessionRow(sessionID)
+}
+
+// SetupTwoFactor starts an enrollment, two-factor stays disabled until the
+// first code is confirmed with EnableTwoFactor
+func (uc AuthUsecase) SetupTwoFactor(accountID string) (*domain.TwoFactorSetup, error) {
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := uc.getTwoFactor(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor != nil && twoFactor.IsEnabled {
+		cerr := cerror.NewAndPrintWithTag("STU00", errors.New("two-factor is already enabled"), global.FRIENDLY_TWO_FACTOR_ENABLED)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("STU01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = uc.twoFactorRepo.SaveSecret(domain.TwoFactor{AccountID: accountID, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+
+	setup := domain.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(secret, config.Config.TwoFactor.Issuer, account.Email),
+	}
+	return &setup, nil
+}
+
+// EnableTwoFactor confirms the enrollment with a code from the authenticator
+// app, the recovery codes are only returned here and stored hashed
+func (uc AuthUsecase) EnableTwoFactor(accountID, code string) ([]string, error) {
+	twoFactor, err := uc.getTwoFactor(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil {
+		cerr := cerror.NewAndPrintWithTag("ETU00", errors.New("two-factor setup is not started"), global.FRIENDLY_TWO_FACTOR_NOT_SETUP)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	if twoFactor.IsEnabled {
+		cerr := cerror.NewAndPrintWithTag("ETU01", errors.New("two-factor is already enabled"), global.FRIENDLY_TWO_FACTOR_ENABLED)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	err = uc.verifyTOTP(*twoFactor, code)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		if cerr.Type == cerror.TYPE_UNAUTHORIZED {
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+		}
+		return nil, cerr
+	}
+
+	var recoveryCodes, recoveryCodeHashes []string
+	for i := 0; i < RECOVERY_CODE_COUNT; i++ {
+		recoveryCode, err := uc.generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		recoveryCodeHashes = append(recoveryCodeHashes, util.HashToken(uc.normalizeRecoveryCode(recoveryCode)))
+	}
+
+	err = uc.twoFactorRepo.EnableTwoFactor(accountID, recoveryCodeHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableTwoFactor requires the password and a current code or recovery
+// code, wrong ones count towards the same lock as VerifyTwoFactor
+func (uc AuthUsecase) DisableTwoFactor(accountID, password, code string) error {
+	err := uc.checkThrottleLock(uc.twoFactorThrottle(accountID))
+	if err != nil {
+		return err
+	}
+
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+	if err != nil {
+		return err
+	}
+
+	err, ok := uc.comparePassword([]byte(password), account.Salt, []byte(account.Password))
+	if !ok || err != nil {
+		cerr := cerror.NewAndPrintWithTag("DTU00", errors.New("incorrect password for account :"+accountID), global.FRIENDLY_INVALID_PASSWORD)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return uc.recordTwoFactorFailure(accountID, "", cerr)
+	}
+
+	twoFactor, err := uc.getTwoFactor(accountID)
+	if err != nil {
+		return err
+	}
+
+	if twoFactor == nil || !twoFactor.IsEnabled {
+		cerr := cerror.NewAndPrintWithTag("DTU01", errors.New("two-factor is not enabled"), global.FRIENDLY_TWO_FACTOR_NOT_SETUP)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return cerr
+	}
+
+	err = uc.verifyTwoFactorCode(*twoFactor, code)
+	if err != nil {
+		return uc.twoFactorFailure(accountID, "", err)
+	}
+	uc.resetTwoFactorFailures(accountID)
+
+	return uc.twoFactorRepo.DeleteTwoFactor(accountID)
 }
 
 func (uc AuthUsecase) generateSalt() ([]byte, error) {
@@ -341,20 +623,32 @@ func (uc AuthUsecase) generateSalt() ([]byte, error) {
 	return salt, nil
 }
 
+// hashPassword hashes with argon2id, the salt is kept in the account and
+// in the encoded hash
 func (uc AuthUsecase) hashPassword(password, salt []byte) (string, error) {
-	var saltedPassword []byte
-	saltedPassword = append(saltedPassword, password...)
-	saltedPassword = append(saltedPassword, salt...)
-
-	hash, err := bcrypt.GenerateFromPassword(saltedPassword, bcrypt.MinCost)
-	if err != nil {
-		return "", cerror.NewAndPrintWithTag("HPA00", err, global.FRIENDLY_MESSAGE)
+	if len(salt) == 0 {
+		return "", cerror.NewAndPrintWithTag("HPA00", errors.New("salt is empty"), global.FRIENDLY_MESSAGE)
 	}
 
-	return string(hash), nil
+	return util.HashArgon2id(password, salt, uc.passwordParams()), nil
 }
 
+// comparePassword also accepts hashes created before argon2id, which are
+// bcrypt hashes of the password with the salt appended
 func (uc AuthUsecase) comparePassword(passwordInput, salt, storedPassword []byte) (error, bool) {
+	if util.IsArgon2id(string(storedPassword)) {
+		ok, err := util.VerifyArgon2id(passwordInput, string(storedPassword))
+		if err != nil {
+			return cerror.NewAndPrintWithTag("CPA01", err, global.FRIENDLY_INVALID_USNME_PASSWORD), false
+		}
+
+		if !ok {
+			return cerror.NewAndPrintWithTag("CPA02", errors.New("password does not match"), global.FRIENDLY_INVALID_USNME_PASSWORD), false
+		}
+
+		return nil, true
+	}
+
 	var saltedPassword []byte
 	saltedPassword = append(saltedPassword, passwordInput...)
 	saltedPassword = append(saltedPassword, salt...)
@@ -367,6 +661,46 @@ func (uc AuthUsecase) comparePassword(passwordInput, salt, storedPassword []byte
 	return nil, true
 }
 
+// passwordNeedsRehash is true for legacy bcrypt hashes and for argon2id
+// hashes created with parameters other than the configured ones
+func (uc AuthUsecase) passwordNeedsRehash(storedPassword string) bool {
+	return util.Argon2idNeedsRehash(storedPassword, uc.passwordParams())
+}
+
+// rehashPassword replaces the stored hash once the password is known to be
+// correct, a failure is logged and does not fail the login
+func (uc AuthUsecase) rehashPassword(account domain.Account, password string) {
+	var err error
+	account.Salt, err = uc.generateSalt()
+	if err != nil {
+		return
+	}
+
+	account.Password, err = uc.hashPassword([]byte(password), account.Salt)
+	if err != nil {
+		return
+	}
+
+	_ = uc.accountRepo.UpdateSaltAndPassword(account)
+}
+
+func (uc AuthUsecase) passwordParams() util.Argon2Params {
+	params := util.DefaultArgon2Params
+
+	passwordHash := config.Config.PasswordHash
+	if passwordHash.Memory > 0 {
+		params.Memory = passwordHash.Memory
+	}
+	if passwordHash.Iterations > 0 {
+		params.Iterations = passwordHash.Iterations
+	}
+	if passwordHash.Parallelism > 0 {
+		params.Parallelism = passwordHash.Parallelism
+	}
+
+	return params
+}
+
 func (uc AuthUsecase) generateEmailConfirmationUrl(account domain.Account) string {
 	url := fmt.Sprintf("%s/email_confirmation?token=%s", config.Config.FEHost, account.EmailToken)
 	return url
@@ -377,11 +711,188 @@ func (uc AuthUsecase) generateResetPasswordUrl(token string) string {
 	return url
 }
 
-func (uc AuthUsecase) createTokenPair(account domain.Account, profile domain.Profile) (*helper.JWTWrapper, error) {
+func (uc AuthUsecase) generateMagicLinkUrl(token string) string {
+	url := fmt.Sprintf("%s/magic_link?token=%s", config.Config.FEHost, token)
+	return url
+}
+
+// getTwoFactor returns nil when the account has never set up two-factor
+func (uc AuthUsecase) getTwoFactor(accountID string) (*domain.TwoFactor, error) {
+	twoFactor, err := uc.twoFactorRepo.GetTwoFactor(accountID)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return twoFactor, nil
+}
+
+func (uc AuthUsecase) createTwoFactorChallenge(accountID string) (string, error) {
+	challengeToken, err := util.GenerateToken(32)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("CTC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	exp := time.Now().Add(TWO_FACTOR_CHALLENGE_EXPIRY).Unix()
+	err = helper.RedisHelper.Set(TWO_FACTOR_CHALLENGE_PREFIX+util.HashToken(challengeToken), accountID, exp)
+	if err != nil {
+		return "", err
+	}
+
+	return challengeToken, nil
+}
+
+// verifyTwoFactorCode accepts a totp code or an unused recovery code
+func (uc AuthUsecase) verifyTwoFactorCode(twoFactor domain.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == util.TOTP_DIGITS {
+		return uc.verifyTOTP(twoFactor, code)
+	}
+
+	codeHash := util.HashToken(uc.normalizeRecoveryCode(code))
+	used, err := uc.twoFactorRepo.UseRecoveryCode(twoFactor.AccountID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		errMsg := fmt.Sprintf("invalid recovery code for account %s", twoFactor.AccountID)
+		cerr := cerror.NewAndPrintWithTag("VTC00", errors.New(errMsg), global.FRIENDLY_INVALID_TWO_FACTOR_CODE)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return cerr
+	}
+
+	return nil
+}
+
+// twoFactorFailure only counts a wrong code, not an error reading it
+func (uc AuthUsecase) twoFactorFailure(accountID, clientIP string, err error) error {
+	cerr, ok := err.(cerror.Error)
+	if !ok || cerr.Type != cerror.TYPE_UNAUTHORIZED {
+		return err
+	}
+
+	return uc.recordTwoFactorFailure(accountID, clientIP, err)
+}
+
+// verifyTOTP rejects a code whose time step has already been used
+func (uc AuthUsecase) verifyTOTP(twoFactor domain.TwoFactor, code string) error {
+	step, ok, err := util.ValidateTOTP(twoFactor.Secret, code, time.Now())
+	if err != nil {
+		return cerror.NewAndPrintWithTag("VTP00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if ok {
+		ok, err = uc.twoFactorRepo.UseStep(twoFactor.AccountID, step)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		errMsg := fmt.Sprintf("invalid totp code for account %s", twoFactor.AccountID)
+		cerr := cerror.NewAndPrintWithTag("VTP01", errors.New(errMsg), global.FRIENDLY_INVALID_TWO_FACTOR_CODE)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return cerr
+	}
+
+	return nil
+}
+
+// generateRecoveryCode returns a code formatted as groups of four characters
+func (uc AuthUsecase) generateRecoveryCode() (string, error) {
+	b := make([]byte, RECOVERY_CODE_BYTES)
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("GRC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+
+	var groups []string
+	for i := 0; i < len(code); i += 4 {
+		end := i + 4
+		if end > len(code) {
+			end = len(code)
+		}
+		groups = append(groups, code[i:end])
+	}
+
+	return strings.Join(groups, "-"), nil
+}
+
+func (uc AuthUsecase) normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
+
+// completeLogin issues the token pair, or a challenge token when the account
+// has two-factor enabled. The token pair is then issued by VerifyTwoFactor
+// once the code is checked
+func (uc AuthUsecase) completeLogin(accountID string, client domain.ClientInfo) (*helper.JWTWrapper, string, error) {
+	twoFactor, err := uc.getTwoFactor(accountID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if twoFactor != nil && twoFactor.IsEnabled {
+		challengeToken, err := uc.createTwoFactorChallenge(accountID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return nil, challengeToken, nil
+	}
+
+	token, err := uc.issueToken(accountID, client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, "", nil
+}
+
+// issueToken creates the token pair of a logged in account and records
+// the login as a new session
+func (uc AuthUsecase) issueToken(accountID string, client domain.ClientInfo) (*helper.JWTWrapper, error) {
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := uc.profileRepo.GetProfile(domain.ProfileFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	session := uc.newSession(accountID, client)
+	token, err := uc.createTokenPair(*account, *profile, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.sessionRepo.InsertSession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	//sessions are only pruned here, an expired one is already hidden by SessionList
+	_ = uc.sessionRepo.DeleteExpiredSessions(accountID)
+
+	return token, nil
+}
+
+// createTokenPair stores the uuids and expiry of the new tokens in session
+func (uc AuthUsecase) createTokenPair(account domain.Account, profile domain.Profile, session *domain.Session) (*helper.JWTWrapper, error) {
 	accessTokenClaims := jwt.MapClaims{}
 	accessTokenClaims["authorized"] = true
 	accessTokenClaims["account_id"] = account.AccountID
 	accessTokenClaims["access_uuid"] = uuid.New().String()
+	accessTokenClaims["session_id"] = session.SessionID
 	accessTokenClaims["email"] = account.Email
 	accessTokenClaims["exp"] = time.Now().Add(15 * time.Minute).Unix()
 	accessTokenClaims["full_name"] = profile.FullName
@@ -389,6 +900,7 @@ func (uc AuthUsecase) createTokenPair(account domain.Account, profile domain.Pro
 	refreshTokenClaims := jwt.MapClaims{}
 	refreshTokenClaims["account_id"] = account.AccountID
 	refreshTokenClaims["refresh_uuid"] = uuid.New().String()
+	refreshTokenClaims["session_id"] = session.SessionID
 
 	rtExp := time.Now().Add(1 * time.Hour)
 	refreshTokenClaims["exp"] = rtExp.Unix()
@@ -410,5 +922,9 @@ func (uc AuthUsecase) createTokenPair(account domain.Account, profile domain.Pro
 		return nil, err
 	}
 
+	session.AccessUUID = accessTokenClaims["access_uuid"].(string)
+	session.RefreshUUID = refreshTokenClaims["refresh_uuid"].(string)
+	session.ExpiresAt = rtExp
+
 	return token, nil
 }
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestComparePassword(t *testing.T) {
+	config.Config.PasswordHash = config.PasswordHashConfig{Memory: 64, Iterations: 1, Parallelism: 1}
+	uc := AuthUsecase{}
+
+	salt := []byte("0123456789abcdef")
+	legacy, err := bcrypt.GenerateFromPassword(append([]byte("correct horse"), salt...), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := uc.hashPassword([]byte("correct horse"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outdated := util.HashArgon2id([]byte("correct horse"), salt, util.Argon2Params{Memory: 128, Iterations: 1, Parallelism: 1})
+
+	tests := []struct {
+		name   string
+		stored string
+		input  string
+		ok     bool
+		rehash bool
+	}{
+		{"legacy bcrypt", string(legacy), "correct horse", true, true},
+		{"legacy bcrypt wrong password", string(legacy), "correct horsf", false, true},
+		{"argon2id", current, "correct horse", true, false},
+		{"argon2id wrong password", current, "correct horsf", false, false},
+		{"argon2id with old params", outdated, "correct horse", true, true},
+		{"malformed argon2id", "$argon2id$v=19$m=64,t=1,p=0$c2FsdA$aGFzaA", "correct horse", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err, ok := uc.comparePassword([]byte(tt.input), salt, []byte(tt.stored))
+			if ok != tt.ok || (err == nil) != tt.ok {
+				t.Errorf("comparePassword = %v, %v, want ok %v", err, ok, tt.ok)
+			}
+			if rehash := uc.passwordNeedsRehash(tt.stored); rehash != tt.rehash {
+				t.Errorf("passwordNeedsRehash = %v, want %v", rehash, tt.rehash)
+			}
+		})
+	}
+}
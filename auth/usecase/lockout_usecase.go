@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	LOGIN_FAILURE_PREFIX = "login_failure:"
+	LOGIN_LOCK_PREFIX    = "login_lock:"
+	LOGIN_FAILURE_WINDOW = 24 * time.Hour
+
+	//failed attempts allowed before the first lock, an ip address is shared
+	//by more people than an account
+	ACCOUNT_FREE_ATTEMPTS    = 5
+	IP_FREE_ATTEMPTS         = 20
+	TWO_FACTOR_FREE_ATTEMPTS = 5
+
+	LOGIN_LOCK_MIN = time.Minute
+	LOGIN_LOCK_MAX = time.Hour
+)
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// loginThrottle counts failed logins of one email or one ip address
+type loginThrottle struct {
+	key          string
+	freeAttempts int64
+	isAccount    bool
+}
+
+func (uc AuthUsecase) loginThrottles(email, clientIP string) []loginThrottle {
+	throttles := []loginThrottle{{
+		key:          "account:" + util.HashToken(strings.ToLower(email)),
+		freeAttempts: ACCOUNT_FREE_ATTEMPTS,
+		isAccount:    true,
+	}}
+
+	if clientIP != "" {
+		throttles = append(throttles, loginThrottle{
+			key:          "ip:" + clientIP,
+			freeAttempts: IP_FREE_ATTEMPTS,
+		})
+	}
+
+	return throttles
+}
+
+// twoFactorThrottle counts wrong codes of an account across every
+// challenge, the password is already known to whoever guesses them
+func (uc AuthUsecase) twoFactorThrottle(accountID string) loginThrottle {
+	return loginThrottle{
+		key:          "2fa:" + accountID,
+		freeAttempts: TWO_FACTOR_FREE_ATTEMPTS,
+	}
+}
+
+// checkLoginLock returns LoginLockedError while the email or the ip
+// address is locked
+func (uc AuthUsecase) checkLoginLock(email, clientIP string) error {
+	return uc.checkThrottleLock(uc.loginThrottles(email, clientIP)...)
+}
+
+func (uc AuthUsecase) checkThrottleLock(throttles ...loginThrottle) error {
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		value, _ := helper.RedisHelper.Get(LOGIN_LOCK_PREFIX + throttle.key)
+		unlockAt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		remaining := time.Until(time.Unix(unlockAt, 0))
+		if remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	if retryAfter > 0 {
+		return domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts the failure and locks the login once the free
+// attempts are used, every further failure doubles the lock. account is
+// nil for an unknown email
+func (uc AuthUsecase) recordLoginFailure(email, clientIP string, account *domain.Account) error {
+	var retryAfter time.Duration
+	for _, throttle := range uc.loginThrottles(email, clientIP) {
+		lock, failures, err := uc.countFailure(throttle)
+		if err != nil {
+			return err
+		}
+
+		if lock > retryAfter {
+			retryAfter = lock
+		}
+
+		//the owner is told once per window, not on every doubled lock
+		if lock > 0 && throttle.isAccount && account != nil && failures == throttle.freeAttempts {
+			uc.sendLockoutMail(*account, clientIP, lock, failures)
+		}
+	}
+
+	if retryAfter > 0 {
+		return domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	cerr := cerror.NewAndPrintWithTag("LGU03",
+		errors.New("incorrect email or password for email :"+email),
+		global.FRIENDLY_INVALID_USNME_PASSWORD)
+	cerr.Type = cerror.TYPE_UNAUTHORIZED
+	return cerr
+}
+
+// recordTwoFactorFailure returns LoginLockedError once the free attempts
+// of the account are used, or codeErr before that
+func (uc AuthUsecase) recordTwoFactorFailure(accountID, clientIP string, codeErr error) error {
+	lock, failures, err := uc.countFailure(uc.twoFactorThrottle(accountID))
+	if err != nil {
+		return err
+	}
+
+	if lock == 0 {
+		return codeErr
+	}
+
+	if failures == TWO_FACTOR_FREE_ATTEMPTS {
+		account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+		if err == nil {
+			uc.sendLockoutMail(*account, clientIP, lock, failures)
+		}
+	}
+
+	return domain.LoginLockedError{RetryAfter: lock}
+}
+
+// countFailure returns the lock set once the free attempts of throttle
+// are used, zero before that
+func (uc AuthUsecase) countFailure(throttle loginThrottle) (time.Duration, int64, error) {
+	now := time.Now()
+	failures, err := helper.RedisHelper.Increment(LOGIN_FAILURE_PREFIX+throttle.key, now.Add(LOGIN_FAILURE_WINDOW).Unix())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if failures < throttle.freeAttempts {
+		return 0, failures, nil
+	}
+
+	lock := uc.loginLockDuration(failures - throttle.freeAttempts)
+	unlockAt := now.Add(lock).Unix()
+	err = helper.RedisHelper.Set(LOGIN_LOCK_PREFIX+throttle.key, unlockAt, unlockAt)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return lock, failures, nil
+}
+
+// resetLoginFailures forgets the failures of the email after a successful
+// login, the ip address keeps its count
+func (uc AuthUsecase) resetLoginFailures(email string) {
+	throttle := uc.loginThrottles(email, "")[0]
+	_ = helper.RedisHelper.Delete(LOGIN_FAILURE_PREFIX + throttle.key)
+}
+
+func (uc AuthUsecase) resetTwoFactorFailures(accountID string) {
+	_ = helper.RedisHelper.Delete(LOGIN_FAILURE_PREFIX + uc.twoFactorThrottle(accountID).key)
+}
+
+func (uc AuthUsecase) loginLockDuration(extraFailures int64) time.Duration {
+	if extraFailures >= 32 {
+		return LOGIN_LOCK_MAX
+	}
+
+	lock := LOGIN_LOCK_MIN * time.Duration(math.Pow(2, float64(extraFailures)))
+	if lock > LOGIN_LOCK_MAX {
+		return LOGIN_LOCK_MAX
+	}
+
+	return lock
+}
+
+// sendLockoutMail does not fail the login response when the mail can not
+// be sent, the error is printed by the mail helper
+func (uc AuthUsecase) sendLockoutMail(account domain.Account, clientIP string, lock time.Duration, failures int64) {
+	if clientIP == "" {
+		clientIP = "an unknown address"
+	}
+
+	msg := fmt.Sprintf(global.LOGIN_LOCKED_TEMPLATE, int(lock.Minutes()), failures, clientIP)
+	to := []string{account.Email}
+	subject := config.Config.LoginLockout.Subject
+	_ = uc.mailHelper.SendMail(to, subject, msg)
+}
+
+// compareDummyPassword spends the same time as a real password check so
+// unknown emails can not be told apart by the response time
+func (uc AuthUsecase) compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		salt, err := uc.generateSalt()
+		if err == nil {
+			dummyPasswordHash, _ = uc.hashPassword([]byte("dummy password"), salt)
+		}
+	})
+
+	uc.comparePassword([]byte(password), nil, []byte(dummyPasswordHash))
+}
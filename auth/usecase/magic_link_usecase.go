@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	MAGIC_LINK_PREFIX = "magic_link:"
+	MAGIC_LINK_EXPIRY = 15 * time.Minute
+
+	//links sent before the first lock, the same lockout counters as login
+	MAGIC_LINK_EMAIL_FREE_SENDS = 3
+	MAGIC_LINK_IP_FREE_SENDS    = 10
+)
+
+func (uc AuthUsecase) magicLinkThrottles(email, clientIP string) []loginThrottle {
+	throttles := []loginThrottle{{
+		key:          "magic_link_email:" + util.HashToken(strings.ToLower(email)),
+		freeAttempts: MAGIC_LINK_EMAIL_FREE_SENDS,
+	}}
+
+	if clientIP != "" {
+		throttles = append(throttles, loginThrottle{
+			key:          "magic_link_ip:" + clientIP,
+			freeAttempts: MAGIC_LINK_IP_FREE_SENDS,
+		})
+	}
+
+	return throttles
+}
+
+// SendMagicLink emails a single use login link, only the hash of the
+// token is stored. Unknown and unverified emails get no link but no error
+// either, so the response does not tell whether the email is registered
+func (uc AuthUsecase) SendMagicLink(email, clientIP string) error {
+	throttles := uc.magicLinkThrottles(email, clientIP)
+	err := uc.checkThrottleLock(throttles...)
+	if err != nil {
+		return err
+	}
+
+	//every send is counted, not only the ones to registered emails
+	for _, throttle := range throttles {
+		_, _, err = uc.countFailure(throttle)
+		if err != nil {
+			return err
+		}
+	}
+
+	filter := domain.AccountFilter{Email: email}
+	account, err := uc.accountRepo.GetAccount(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	//the link would otherwise verify an email someone else signed up with
+	if !account.IsVerified {
+		return nil
+	}
+
+	token, err := util.GenerateToken(32)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("SML01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	exp := time.Now().Add(MAGIC_LINK_EXPIRY).Unix()
+	err = helper.RedisHelper.Set(MAGIC_LINK_PREFIX+util.HashToken(token), account.AccountID, exp)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf(global.MAGIC_LINK_TEMPLATE, uc.generateMagicLinkUrl(token), int(MAGIC_LINK_EXPIRY.Minutes()))
+	to := []string{account.Email}
+	subject := config.Config.MagicLink.Subject
+	return uc.mailHelper.SendMail(to, subject, msg)
+}
+
+// LoginWithMagicLink consumes the token in a single redis operation so a
+// link cannot be used twice, two-factor is still asked like Login
+func (uc AuthUsecase) LoginWithMagicLink(token string, client domain.ClientInfo) (*helper.JWTWrapper, string, error) {
+	//Pop runs on its own pooled connection, only one request gets the
+	//account of the link
+	accountID, err := helper.RedisHelper.Pop(MAGIC_LINK_PREFIX + util.HashToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if accountID == "" {
+		cerr := cerror.NewAndPrintWithTag("LML00", errors.New("magic link is not found or expired"), global.FRIENDLY_MAGIC_LINK_INVALID)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, "", cerr
+	}
+
+	return uc.completeLogin(accountID, client)
+}
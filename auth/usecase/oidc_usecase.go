@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	OIDC_STATE_PREFIX = "oidc_state:"
+	OIDC_STATE_EXPIRY = 10 * time.Minute
+)
+
+// oidcState is kept in redis between the redirect and the callback
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// BeginOIDCLogin returns the authorization url of the provider and its
+// state, the state can only be redeemed once by FinishOIDCLogin from the
+// browser it is returned to
+func (uc AuthUsecase) BeginOIDCLogin(provider string) (string, string, error) {
+	stateToken, err := util.GenerateToken(32)
+	if err != nil {
+		return "", "", cerror.NewAndPrintWithTag("BOL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	state := oidcState{Provider: provider}
+	state.Nonce, err = util.GenerateToken(32)
+	if err != nil {
+		return "", "", cerror.NewAndPrintWithTag("BOL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	state.CodeVerifier, err = util.GenerateToken(32)
+	if err != nil {
+		return "", "", cerror.NewAndPrintWithTag("BOL02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	authorizationURL, err := uc.oidcHelper.AuthorizationURL(provider, stateToken, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		return "", "", cerror.NewAndPrintWithTag("BOL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	exp := time.Now().Add(OIDC_STATE_EXPIRY).Unix()
+	err = helper.RedisHelper.Set(OIDC_STATE_PREFIX+util.HashToken(stateToken), string(value), exp)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authorizationURL, stateToken, nil
+}
+
+// FinishOIDCLogin signs in the account linked to the provider identity. An
+// identity seen for the first time is linked to the account with the same
+// verified email, or to a new account when there is none. browserState is
+// the state kept by the browser that began the login, a callback replayed
+// into another browser does not carry it
+func (uc AuthUsecase) FinishOIDCLogin(code, stateToken, browserState string, client domain.ClientInfo) (*helper.JWTWrapper, string, error) {
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(stateToken), []byte(browserState)) != 1 {
+		cerr := cerror.NewAndPrintWithTag("FOL01", errors.New("oidc state does not match the browser"), global.FRIENDLY_OIDC_FAILED)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, "", cerr
+	}
+
+	value, err := helper.RedisHelper.Pop(OIDC_STATE_PREFIX + util.HashToken(stateToken))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var state oidcState
+	if value == "" || json.Unmarshal([]byte(value), &state) != nil {
+		cerr := cerror.NewAndPrintWithTag("FOL00", errors.New("oidc state is not found or expired"), global.FRIENDLY_OIDC_FAILED)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, "", cerr
+	}
+
+	identity, err := uc.oidcHelper.Exchange(state.Provider, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, "", uc.oidcError(err)
+	}
+
+	accountID, err := uc.getOIDCAccount(*identity)
+	if err != nil {
+		return nil, "", err
+	}
+
+	//the provider only replaces the password, two-factor is still asked
+	return uc.completeLogin(accountID, client)
+}
+
+// getOIDCAccount returns the account id linked to the identity, linking or
+// creating the account on the first login
+func (uc AuthUsecase) getOIDCAccount(identity helper.OIDCIdentity) (string, error) {
+	linked, err := uc.identityRepo.GetIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return linked.AccountID, nil
+	}
+
+	cerr, ok := err.(cerror.Error)
+	if !ok || cerr.Err != sql.ErrNoRows {
+		return "", err
+	}
+
+	//an unverified email could be used to take over an existing account
+	if identity.Email == "" || !identity.EmailVerified {
+		errMsg := fmt.Sprintf("email of %s identity %s is not verified", identity.Provider, identity.Subject)
+		cerr := cerror.NewAndPrintWithTag("GOA00", errors.New(errMsg), global.FRIENDLY_OIDC_EMAIL_NOT_VERIFIED)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return "", cerr
+	}
+
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{Email: identity.Email})
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if !ok || cerr.Err != sql.ErrNoRows {
+			return "", err
+		}
+
+		account, err = uc.createOIDCAccount(identity)
+		if err != nil {
+			return "", err
+		}
+	} else if !account.IsVerified {
+		//whoever registered the email never proved owning it and may know
+		//its password, linking would hand them the account
+		errMsg := fmt.Sprintf("%s identity %s matches unverified account %s", identity.Provider, identity.Subject, account.AccountID)
+		cerr := cerror.NewAndPrintWithTag("GOA01", errors.New(errMsg), global.FRIENDLY_OIDC_ACCOUNT_UNVERIFIED)
+		cerr.Type = cerror.TYPE_CONFLICT
+		return "", cerr
+	}
+
+	err = uc.identityRepo.InsertIdentity(domain.AccountIdentity{
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		AccountID: account.AccountID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return account.AccountID, nil
+}
+
+// createOIDCAccount creates a verified account with a random password, the
+// owner can set a password later through reset password
+func (uc AuthUsecase) createOIDCAccount(identity helper.OIDCIdentity) (*domain.Account, error) {
+	password, err := util.GenerateToken(32)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("COA00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	account := domain.Account{
+		Email:      identity.Email,
+		IsVerified: true,
+	}
+	account.Salt, err = uc.generateSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	account.Password, err = uc.hashPassword([]byte(password), account.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	insertedAccount, err := uc.accountRepo.InsertAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := identity.Name
+	if fullName == "" {
+		fullName = identity.Email
+	}
+
+	profile := domain.Profile{AccountID: insertedAccount.AccountID, FullName: fullName}
+	err = uc.profileRepo.InsertProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return insertedAccount, nil
+}
+
+// oidcError marks a failed exchange as unauthorized, a provider that is
+// not configured stays a bad request
+func (uc AuthUsecase) oidcError(err error) error {
+	cerr, ok := err.(cerror.Error)
+	if ok && cerr.Type == cerror.TYPE_UNDEFINED {
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return cerr
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/helper"
+)
+
+type fakeIdentityRepo struct {
+	identities map[string]domain.AccountIdentity
+	inserted   []domain.AccountIdentity
+}
+
+func (r *fakeIdentityRepo) GetIdentity(provider, subject string) (*domain.AccountIdentity, error) {
+	identity, ok := r.identities[provider+":"+subject]
+	if !ok {
+		return nil, cerror.Error{Tag: "TST00", Err: sql.ErrNoRows}
+	}
+	return &identity, nil
+}
+
+func (r *fakeIdentityRepo) InsertIdentity(identity domain.AccountIdentity) error {
+	r.inserted = append(r.inserted, identity)
+	return nil
+}
+
+type fakeAccountRepo struct {
+	domain.IAccountRepository
+	accounts []domain.Account
+}
+
+func (r *fakeAccountRepo) GetAccount(filter domain.AccountFilter) (*domain.Account, error) {
+	for _, account := range r.accounts {
+		if account.Email == filter.Email {
+			return &account, nil
+		}
+	}
+	return nil, cerror.Error{Tag: "TST01", Err: sql.ErrNoRows}
+}
+
+func TestGetOIDCAccount(t *testing.T) {
+	accounts := []domain.Account{
+		{AccountID: "verified-account", Email: "budi@example.com", IsVerified: true},
+		{AccountID: "unverified-account", Email: "siti@example.com", IsVerified: false},
+	}
+
+	tests := []struct {
+		name      string
+		identity  helper.OIDCIdentity
+		linked    map[string]domain.AccountIdentity
+		want      string
+		wantType  int
+		wantLinks int
+	}{
+		{
+			name:      "verified email links the account",
+			identity:  helper.OIDCIdentity{Provider: "mock", Subject: "1", Email: "budi@example.com", EmailVerified: true},
+			want:      "verified-account",
+			wantLinks: 1,
+		},
+		{
+			name:     "unverified email does not link the account",
+			identity: helper.OIDCIdentity{Provider: "mock", Subject: "1", Email: "budi@example.com", EmailVerified: false},
+			wantType: cerror.TYPE_UNAUTHORIZED,
+		},
+		{
+			name:     "missing email does not link",
+			identity: helper.OIDCIdentity{Provider: "mock", Subject: "1", EmailVerified: true},
+			wantType: cerror.TYPE_UNAUTHORIZED,
+		},
+		{
+			name:     "unverified account is not linked",
+			identity: helper.OIDCIdentity{Provider: "mock", Subject: "1", Email: "siti@example.com", EmailVerified: true},
+			wantType: cerror.TYPE_CONFLICT,
+		},
+		{
+			name:     "linked identity signs in without email",
+			identity: helper.OIDCIdentity{Provider: "mock", Subject: "1"},
+			linked:   map[string]domain.AccountIdentity{"mock:1": {Provider: "mock", Subject: "1", AccountID: "verified-account"}},
+			want:     "verified-account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identityRepo := &fakeIdentityRepo{identities: tt.linked}
+			uc := AuthUsecase{
+				accountRepo:  &fakeAccountRepo{accounts: accounts},
+				identityRepo: identityRepo,
+			}
+
+			accountID, err := uc.getOIDCAccount(tt.identity)
+			if tt.want != "" {
+				if err != nil || accountID != tt.want {
+					t.Fatalf("getOIDCAccount = %q, %v, want %q", accountID, err, tt.want)
+				}
+			} else {
+				cerr, ok := err.(cerror.Error)
+				if !ok || cerr.Type != tt.wantType {
+					t.Fatalf("getOIDCAccount error = %v, want type %d", err, tt.wantType)
+				}
+			}
+
+			if len(identityRepo.inserted) != tt.wantLinks {
+				t.Errorf("linked %d identities, want %d", len(identityRepo.inserted), tt.wantLinks)
+			}
+		})
+	}
+}
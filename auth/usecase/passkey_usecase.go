@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	PASSKEY_REGISTER_PREFIX = "passkey_register:"
+	PASSKEY_LOGIN_PREFIX    = "passkey_login:"
+	PASSKEY_TIMEOUT         = 5 * time.Minute
+	PASSKEY_DEFAULT_NAME    = "Passkey"
+)
+
+// BeginPasskeyRegistration creates the options of a registration ceremony,
+// the challenge can only be used once by the same account
+func (uc AuthUsecase) BeginPasskeyRegistration(accountID string) (*domain.PasskeyCreationOptions, error) {
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := uc.profileRepo.GetProfile(domain.ProfileFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	passkeyList, err := uc.passkeyRepo.PasskeyList(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := uc.createPasskeyChallenge(PASSKEY_REGISTER_PREFIX, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := domain.PasskeyCreationOptions{
+		Challenge: challenge,
+		RP: domain.PasskeyRP{
+			ID:   uc.webAuthnHelper.RPID(),
+			Name: uc.webAuthnHelper.RPName(),
+		},
+		User: domain.PasskeyUser{
+			ID:          helper.EncodeWebAuthnID([]byte(accountID)),
+			Name:        account.Email,
+			DisplayName: profile.FullName,
+		},
+		Timeout:     PASSKEY_TIMEOUT.Milliseconds(),
+		Attestation: "none",
+		AuthenticatorSelection: domain.PasskeySelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		ExcludeCredentials: []domain.PasskeyDescriptor{},
+	}
+
+	for _, alg := range helper.WebAuthnAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, domain.PasskeyCredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	//an authenticator already registered to the account is not registered twice
+	for _, passkey := range passkeyList {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, domain.PasskeyDescriptor{
+			Type:       "public-key",
+			ID:         helper.EncodeWebAuthnID(passkey.CredentialID),
+			Transports: passkey.Transports,
+		})
+	}
+
+	return &options, nil
+}
+
+func (uc AuthUsecase) FinishPasskeyRegistration(accountID string, registration domain.PasskeyRegistration) (*domain.Passkey, error) {
+	err := uc.consumePasskeyChallenge(PASSKEY_REGISTER_PREFIX, registration.ClientDataJSON, helper.WEBAUTHN_CEREMONY_CREATE, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := uc.webAuthnHelper.VerifyAttestation(registration.AttestationObject)
+	if err != nil {
+		return nil, uc.passkeyError(err)
+	}
+
+	passkey := domain.Passkey{
+		CredentialID: credential.CredentialID,
+		AccountID:    accountID,
+		Name:         registration.Name,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.SignCount,
+		Transports:   registration.Transports,
+		CreatedAt:    time.Now(),
+	}
+	if passkey.Name == "" {
+		passkey.Name = PASSKEY_DEFAULT_NAME
+	}
+
+	err = uc.passkeyRepo.InsertPasskey(passkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkey, nil
+}
+
+func (uc AuthUsecase) BeginPasskeyLogin() (*domain.PasskeyRequestOptions, error) {
+	challenge, err := uc.createPasskeyChallenge(PASSKEY_LOGIN_PREFIX, "login")
+	if err != nil {
+		return nil, err
+	}
+
+	options := domain.PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             uc.webAuthnHelper.RPID(),
+		Timeout:          PASSKEY_TIMEOUT.Milliseconds(),
+		UserVerification: "required",
+	}
+	return &options, nil
+}
+
+// FinishPasskeyLogin issues the same token pair as a password login, the
+// passkey requires user verification so two-factor is not asked again
+func (uc AuthUsecase) FinishPasskeyLogin(assertion domain.PasskeyAssertion, client domain.ClientInfo) (*helper.JWTWrapper, error) {
+	err := uc.consumePasskeyChallenge(PASSKEY_LOGIN_PREFIX, assertion.ClientDataJSON, helper.WEBAUTHN_CEREMONY_GET, "login")
+	if err != nil {
+		return nil, err
+	}
+
+	passkey, err := uc.passkeyRepo.GetPasskey(assertion.CredentialID)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_PASSKEY_INVALID
+			cerr.Type = cerror.TYPE_UNAUTHORIZED
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	if len(assertion.UserHandle) > 0 && !bytes.Equal(assertion.UserHandle, []byte(passkey.AccountID)) {
+		errMsg := fmt.Sprintf("user handle does not match the owner of passkey of account %s", passkey.AccountID)
+		cerr := cerror.NewAndPrintWithTag("FPL00", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	signCount, err := uc.webAuthnHelper.VerifyAssertion(passkey.PublicKey, assertion.AuthenticatorData, assertion.ClientDataJSON, assertion.Signature)
+	if err != nil {
+		return nil, uc.passkeyError(err)
+	}
+
+	//a sign count that does not increase means the authenticator may be cloned
+	if !helper.WebAuthnSignCountValid(passkey.SignCount, signCount) {
+		errMsg := fmt.Sprintf("sign count %d is not greater than the stored %d for account %s", signCount, passkey.SignCount, passkey.AccountID)
+		cerr := cerror.NewAndPrintWithTag("FPL01", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	err = uc.passkeyRepo.UpdateSignCount(passkey.CredentialID, signCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.issueToken(passkey.AccountID, client)
+}
+
+func (uc AuthUsecase) PasskeyList(accountID string) ([]domain.Passkey, error) {
+	return uc.passkeyRepo.PasskeyList(accountID)
+}
+
+func (uc AuthUsecase) RenamePasskey(credentialID []byte, accountID, name string) error {
+	if name == "" {
+		name = PASSKEY_DEFAULT_NAME
+	}
+
+	return uc.passkeyRepo.UpdateName(credentialID, accountID, name)
+}
+
+func (uc AuthUsecase) RevokePasskey(credentialID []byte, accountID string) error {
+	return uc.passkeyRepo.DeletePasskey(credentialID, accountID)
+}
+
+func (uc AuthUsecase) createPasskeyChallenge(prefix, value string) (string, error) {
+	challenge, err := util.GenerateToken(32)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("CPC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	exp := time.Now().Add(PASSKEY_TIMEOUT).Unix()
+	err = helper.RedisHelper.Set(prefix+challenge, value, exp)
+	if err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// consumePasskeyChallenge verifies the client data and removes the challenge
+// it signed, expected is the value stored when the challenge was created
+func (uc AuthUsecase) consumePasskeyChallenge(prefix string, clientDataJSON []byte, ceremony, expected string) error {
+	challenge, err := uc.webAuthnHelper.VerifyClientData(clientDataJSON, ceremony)
+	if err != nil {
+		return uc.passkeyError(err)
+	}
+
+	value, err := helper.RedisHelper.Pop(prefix + challenge)
+	if err != nil {
+		return err
+	}
+
+	if value == "" || value != expected {
+		cerr := cerror.NewAndPrintWithTag("CPC01", errors.New("passkey challenge is not found or expired"), global.FRIENDLY_PASSKEY_INVALID)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return cerr
+	}
+
+	return nil
+}
+
+// passkeyError marks a failed webauthn verification as unauthorized
+func (uc AuthUsecase) passkeyError(err error) error {
+	cerr, ok := err.(cerror.Error)
+	if ok {
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return cerr
+	}
+	return err
+}
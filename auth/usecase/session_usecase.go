@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+// SessionList returns the devices the account is signed in on, the most
+// recently used first
+func (uc AuthUsecase) SessionList(accountID string) ([]domain.Session, error) {
+	return uc.sessionRepo.SessionList(accountID)
+}
+
+func (uc AuthUsecase) RevokeSession(sessionID, accountID string) error {
+	session, err := uc.getSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	//a session of another account is reported the same as a missing one
+	if session == nil || session.AccountID != accountID {
+		err := fmt.Errorf("session %s is not found for account %s", sessionID, accountID)
+		cerr := cerror.NewAndPrintWithTag("RVS00", err, global.FRIENDLY_SESSION_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return cerr
+	}
+
+	return uc.deleteSession(*session)
+}
+
+func (uc AuthUsecase) RevokeAllSessions(accountID string) error {
+	sessionList, err := uc.sessionRepo.SessionList(accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessionList {
+		err = uc.deleteSession(session)
+		if err != nil {
+			return err
+		}
+	}
+
+	return uc.sessionRepo.DeleteExpiredSessions(accountID)
+}
+
+func (uc AuthUsecase) newSession(accountID string, client domain.ClientInfo) domain.Session {
+	now := time.Now()
+	return domain.Session{
+		SessionID:  uuid.New().String(),
+		AccountID:  accountID,
+		DeviceName: util.DeviceName(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+}
+
+// getSession returns nil when the session is revoked, sessionID is empty
+// for tokens issued before sessions were recorded
+func (uc AuthUsecase) getSession(sessionID string) (*domain.Session, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+
+	session, err := uc.sessionRepo.GetSession(sessionID)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// deleteSession removes the tokens of the session from redis, which signs
+// the device out even before its access token expires
+func (uc AuthUsecase) deleteSession(session domain.Session) error {
+	err := helper.RedisHelper.Delete(session.AccessUUID)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DSU00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = helper.RedisHelper.Delete(session.RefreshUUID)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DSU01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return uc.deleteSessionRow(session.SessionID)
+}
+
+// refreshTokenReused revokes the session of a refresh token that has been
+// used before, either the token was stolen or the copy of its owner was,
+// so both have to log in again
+func (uc AuthUsecase) refreshTokenReused(session domain.Session) error {
+	err := uc.deleteSession(session)
+	if err != nil {
+		return err
+	}
+
+	errMsg := fmt.Sprintf("refresh token of session %s of account %s is reused", session.SessionID, session.AccountID)
+	cerr := cerror.NewAndPrintWithTag("RTR00", errors.New(errMsg), global.FRIENDLY_TOKEN_REUSED)
+	cerr.Type = cerror.TYPE_EXPIRED
+	return cerr
+}
+
+// deleteSessionRow ignores a session that is already deleted, a device can
+// be signed out from two places at once
+func (uc AuthUsecase) deleteSessionRow(sessionID string) error {
+	err := uc.sessionRepo.DeleteSession(sessionID)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if !ok || cerr.Type != cerror.TYPE_NOT_FOUND {
+			return err
+		}
+	}
+
+	return nil
+}
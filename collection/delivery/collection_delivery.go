@@ -0,0 +1,268 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	validator "github.com/go-playground/validator/v10"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type InsertCollectionRequest struct {
+	Name          string `json:"name" binding:"required,max=255"`
+	Description   string `json:"description" binding:"max=1000"`
+	CoverImageURL string `json:"cover_image_url"`
+}
+
+type InsertCollectionResponse struct {
+	Message    []string           `json:"message"`
+	Collection *domain.Collection `json:"collection,omitempty"`
+}
+
+type UpdateCollectionRequest struct {
+	CollectionID  string `json:"collection_id" binding:"required"`
+	Name          string `json:"name" binding:"required,max=255"`
+	Description   string `json:"description" binding:"max=1000"`
+	CoverImageURL string `json:"cover_image_url"`
+}
+
+type DeleteCollectionRequest struct {
+	CollectionID string `json:"collection_id" binding:"required"`
+}
+
+type CollectionPostRequest struct {
+	CollectionID string `json:"collection_id" binding:"required"`
+	PostID       string `json:"post_id" binding:"required"`
+}
+
+type CollectionResponse struct {
+	Message []string `json:"message"`
+}
+
+type CollectionListResponse struct {
+	Message        string              `json:"message"`
+	CollectionList []domain.Collection `json:"collection_list"`
+}
+
+/* #endregion */
+
+type CollectionHandler struct {
+	useCase domain.ICollectionUsecase
+}
+
+func NewCollectionHandler(router *gin.Engine, collectionUsecase domain.ICollectionUsecase) {
+	handler := &CollectionHandler{
+		useCase: collectionUsecase,
+	}
+
+	router.POST("/api/collection", handler.InsertCollection)
+	router.GET("/api/collection", handler.CollectionList)
+	router.POST("/api/collection/update", handler.UpdateCollection)
+	router.POST("/api/collection/delete", handler.DeleteCollection)
+	router.POST("/api/collection/post/add", handler.AddPost)
+	router.POST("/api/collection/post/remove", handler.RemovePost)
+}
+
+func (ch CollectionHandler) InsertCollection(c *gin.Context) {
+	var (
+		request   InsertCollectionRequest
+		response  InsertCollectionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("ICH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+
+	var collection domain.Collection
+	collection.Name = p.Sanitize(request.Name)
+	collection.Description = p.Sanitize(request.Description)
+	collection.CoverImageURL = request.CoverImageURL
+	collection.AccountID = accountID
+
+	storedCollection, err := ch.useCase.InsertCollection(collection)
+	if err != nil {
+		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Collection = storedCollection
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (ch CollectionHandler) CollectionList(c *gin.Context) {
+	var (
+		response  CollectionListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	collectionList, err := ch.useCase.CollectionList(accountID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.CollectionList = collectionList
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ch CollectionHandler) UpdateCollection(c *gin.Context) {
+	var (
+		request   UpdateCollectionRequest
+		response  CollectionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("UCH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+
+	var collection domain.Collection
+	collection.CollectionID = request.CollectionID
+	collection.Name = p.Sanitize(request.Name)
+	collection.Description = p.Sanitize(request.Description)
+	collection.CoverImageURL = request.CoverImageURL
+	collection.AccountID = accountID
+
+	err = ch.useCase.UpdateCollection(collection)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ch CollectionHandler) DeleteCollection(c *gin.Context) {
+	var (
+		request   DeleteCollectionRequest
+		response  CollectionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("DCH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ch.useCase.DeleteCollection(request.CollectionID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ch CollectionHandler) AddPost(c *gin.Context) {
+	var (
+		request   CollectionPostRequest
+		response  CollectionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("APH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ch.useCase.AddPost(request.CollectionID, request.PostID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ch CollectionHandler) RemovePost(c *gin.Context) {
+	var (
+		request   CollectionPostRequest
+		response  CollectionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("RCH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ch.useCase.RemovePost(request.CollectionID, request.PostID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ch CollectionHandler) validationMessage(tag string, err error, request interface{}) []string {
+	var messages []string
+	cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+
+	valError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{cerr.FriendlyMessageWithTag()}
+	}
+
+	for _, elem := range valError {
+		fieldName := elem.Field()
+		field, _ := reflect.TypeOf(request).Elem().FieldByName(fieldName)
+		jsonField, _ := field.Tag.Lookup("json")
+
+		switch elem.Tag() {
+		case "required":
+			msg := fmt.Sprintf(global.ERR_REQUIRED_FORMATTER, jsonField)
+			messages = append(messages, msg)
+			break
+
+		case "max":
+			msg := fmt.Sprintf(global.ERR_MAX_CHAR, jsonField, elem.Param())
+			messages = append(messages, msg)
+			break
+		}
+	}
+
+	return messages
+}
+
+func (ch CollectionHandler) errorStatus(cerr cerror.Error) int {
+	if cerr.Type == cerror.TYPE_NOT_FOUND {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,322 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+type MySqlCollectionRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlCollectionRepository(db *sql.DB) domain.ICollectionRepository {
+	return &MySqlCollectionRepository{
+		Db: db,
+	}
+}
+
+func (cr MySqlCollectionRepository) InsertCollection(collection domain.Collection) (*domain.Collection, error) {
+	if collection.CollectionID == "" {
+		collection.CollectionID = util.GenerateUUID()
+	}
+	collection.CreatedAt = time.Now()
+	collection.LastUpdated = collection.CreatedAt
+
+	/*start create query*/
+	query := sq.Insert("collection").
+		Columns("collection_id", "name", "description", "cover_image_url", "created_at", "last_updated", "account_id").
+		Values(collection.CollectionID, collection.Name, collection.Description, collection.CoverImageURL,
+			collection.CreatedAt, collection.LastUpdated, collection.AccountID)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICR00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ICR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ICR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &collection, nil
+	/*end insert execution*/
+}
+
+func (cr MySqlCollectionRepository) UpdateCollection(collection domain.Collection) error {
+	query := sq.Update("collection").
+		Set("name", collection.Name).
+		Set("description", collection.Description).
+		Set("cover_image_url", collection.CoverImageURL).
+		Set("last_updated", time.Now()).
+		Where(sq.Eq{
+			"collection_id": collection.CollectionID,
+			"account_id":    collection.AccountID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UCR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UCR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UCR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UCR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UCR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (cr MySqlCollectionRepository) DeleteCollection(collectionID, accountID string) error {
+	query := sq.Delete("collection").
+		Where(sq.Eq{
+			"collection_id": collectionID,
+			"account_id":    accountID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	//collection_post rows are removed by the foreign key cascade
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DCR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DCR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (cr MySqlCollectionRepository) GetCollection(filter domain.CollectionFilter) (*domain.Collection, error) {
+	query := cr.selectQuery(filter)
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GCR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := cr.Db.QueryRow(sqlString, args...)
+
+	collection := new(domain.Collection)
+	err = cr.scanCollection(row, collection)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GCR01", err, global.FRIENDLY_COLLECTION_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return collection, nil
+}
+
+func (cr MySqlCollectionRepository) CollectionList(filter domain.CollectionFilter) ([]domain.Collection, error) {
+	query := cr.selectQuery(filter).OrderBy("collection.name ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("CLR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := cr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("CLR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var collectionList []domain.Collection
+	for rows.Next() {
+		var collection domain.Collection
+		err = cr.scanCollection(rows, &collection)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("CLR02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		collectionList = append(collectionList, collection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("CLR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return collectionList, nil
+}
+
+func (cr MySqlCollectionRepository) AddPost(collectionID, postID string) error {
+	//adding a post twice is not an error
+	query := sq.Insert("collection_post").
+		Options("IGNORE").
+		Columns("collection_id", "post_id", "date").
+		Values(collectionID, postID, time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("APC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("APC01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("APC02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("APC03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("APC04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (cr MySqlCollectionRepository) RemovePost(collectionID, postID string) error {
+	query := sq.Delete("collection_post").
+		Where(sq.Eq{
+			"collection_id": collectionID,
+			"post_id":       postID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("RPC00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("RPC01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("RPC02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("RPC03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("RPC04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (cr MySqlCollectionRepository) selectQuery(filter domain.CollectionFilter) sq.SelectBuilder {
+	query := sq.Select(`collection.collection_id, collection.name, IFNULL(collection.description, ''),
+		IFNULL(collection.cover_image_url, ''), collection.created_at, collection.last_updated,
+		collection.account_id, COUNT(collection_post.post_id)`).
+		From("collection").
+		LeftJoin("collection_post ON collection_post.collection_id = collection.collection_id").
+		GroupBy("collection.collection_id")
+
+	if filter.CollectionID != "" {
+		query = query.Where(sq.Eq{"collection.collection_id": filter.CollectionID})
+	}
+
+	if filter.AccountID != "" {
+		query = query.Where(sq.Eq{"collection.account_id": filter.AccountID})
+	}
+
+	if filter.PostID != "" {
+		query = query.Where("collection.collection_id IN (SELECT collection_id FROM collection_post WHERE post_id = ?)", filter.PostID)
+	}
+
+	return query
+}
+
+func (cr MySqlCollectionRepository) scanCollection(row interface{ Scan(...interface{}) error }, collection *domain.Collection) error {
+	return row.Scan(
+		&collection.CollectionID,
+		&collection.Name,
+		&collection.Description,
+		&collection.CoverImageURL,
+		&collection.CreatedAt,
+		&collection.LastUpdated,
+		&collection.AccountID,
+		&collection.PostCount,
+	)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"database/sql"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type CollectionUsecase struct {
+	collectionRepo domain.ICollectionRepository
+	postRepo       domain.IPostRepository
+}
+
+func NewCollectionUsecase(collectionRepository domain.ICollectionRepository,
+	postRepository domain.IPostRepository) domain.ICollectionUsecase {
+	return &CollectionUsecase{
+		collectionRepo: collectionRepository,
+		postRepo:       postRepository,
+	}
+}
+
+func (uc CollectionUsecase) InsertCollection(collection domain.Collection) (*domain.Collection, error) {
+	return uc.collectionRepo.InsertCollection(collection)
+}
+
+func (uc CollectionUsecase) UpdateCollection(collection domain.Collection) error {
+	_, err := uc.getOwnedCollection(collection.CollectionID, collection.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return uc.collectionRepo.UpdateCollection(collection)
+}
+
+func (uc CollectionUsecase) DeleteCollection(collectionID, accountID string) error {
+	_, err := uc.getOwnedCollection(collectionID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return uc.collectionRepo.DeleteCollection(collectionID, accountID)
+}
+
+func (uc CollectionUsecase) CollectionList(accountID string) ([]domain.Collection, error) {
+	filter := domain.CollectionFilter{AccountID: accountID}
+	return uc.collectionRepo.CollectionList(filter)
+}
+
+func (uc CollectionUsecase) AddPost(collectionID, postID, accountID string) error {
+	_, err := uc.getOwnedCollection(collectionID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.checkOwnedPost(postID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return uc.collectionRepo.AddPost(collectionID, postID)
+}
+
+func (uc CollectionUsecase) RemovePost(collectionID, postID, accountID string) error {
+	_, err := uc.getOwnedCollection(collectionID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return uc.collectionRepo.RemovePost(collectionID, postID)
+}
+
+func (uc CollectionUsecase) getOwnedCollection(collectionID, accountID string) (*domain.Collection, error) {
+	filter := domain.CollectionFilter{
+		CollectionID: collectionID,
+		AccountID:    accountID,
+	}
+	return uc.collectionRepo.GetCollection(filter)
+}
+
+func (uc CollectionUsecase) checkOwnedPost(postID, accountID string) error {
+	filter := domain.PostFilter{
+		PostID:    postID,
+		AccountID: accountID,
+	}
+	_, err := uc.postRepo.GetPost(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return cerr
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	validator "github.com/go-playground/validator/v10"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type InsertCommentRequest struct {
+	PostID   string `json:"post_id" binding:"required"`
+	ParentID string `json:"parent_id"`
+	Content  string `json:"content" binding:"required,max=2000"`
+}
+
+type InsertCommentResponse struct {
+	Message []string        `json:"message"`
+	Comment *CommentElement `json:"comment,omitempty"`
+}
+
+type DeleteCommentRequest struct {
+	CommentID string `json:"comment_id" binding:"required"`
+}
+
+type DeleteCommentResponse struct {
+	Message []string `json:"message"`
+}
+
+type CommentListRequest struct {
+	PostID string `form:"post_id" binding:"required"`
+}
+
+type CommentListResponse struct {
+	Message     string           `json:"message"`
+	CommentList []CommentElement `json:"comment_list"`
+}
+
+type CommentElement struct {
+	CommentID  string           `json:"comment_id"`
+	PostID     string           `json:"post_id"`
+	ParentID   string           `json:"parent_id,omitempty"`
+	Content    string           `json:"content"`
+	Author     CommentAuthor    `json:"author"`
+	Date       string           `json:"date"`
+	HiddenDate string           `json:"hidden_date"`
+	Replies    []CommentElement `json:"replies"`
+}
+
+type CommentAuthor struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+/* #endregion */
+
+type CommentHandler struct {
+	useCase domain.ICommentUsecase
+}
+
+func NewCommentHandler(router *gin.Engine, commentUsecase domain.ICommentUsecase) {
+	handler := &CommentHandler{
+		useCase: commentUsecase,
+	}
+
+	router.POST("/api/comment", handler.InsertComment)
+	router.GET("/api/comment", handler.CommentList)
+	router.POST("/api/comment/delete", handler.DeleteComment)
+}
+
+func (ch CommentHandler) InsertComment(c *gin.Context) {
+	var (
+		request   InsertCommentRequest
+		response  InsertCommentResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("IMH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+
+	var comment domain.Comment
+	comment.PostID = request.PostID
+	comment.ParentID = request.ParentID
+	comment.Content = p.Sanitize(request.Content)
+	comment.AccountID = accountID
+
+	storedComment, err := ch.useCase.InsertComment(comment)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	element := ch.createCommentElement(*storedComment)
+	response.Comment = &element
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (ch CommentHandler) CommentList(c *gin.Context) {
+	var (
+		request   CommentListRequest
+		response  CommentListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("CLH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	commentList, err := ch.useCase.CommentList(request.PostID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	response.CommentList = ch.createCommentElementList(commentList)
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ch CommentHandler) DeleteComment(c *gin.Context) {
+	var (
+		request   DeleteCommentRequest
+		response  DeleteCommentResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = ch.validationMessage("DMH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ch.useCase.DeleteComment(request.CommentID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ch.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ch CommentHandler) createCommentElementList(commentList []domain.Comment) []CommentElement {
+	elements := []CommentElement{}
+	for _, comment := range commentList {
+		elements = append(elements, ch.createCommentElement(comment))
+	}
+
+	return elements
+}
+
+func (ch CommentHandler) createCommentElement(comment domain.Comment) CommentElement {
+	var element CommentElement
+	element.CommentID = comment.CommentID
+	element.PostID = comment.PostID
+	element.ParentID = comment.ParentID
+	element.Content = comment.Content
+	element.Author.Username = comment.Author.Username
+	element.Author.FullName = comment.Author.FullName
+	element.Date = comment.Date.Format(global.TIME_FORMAT)
+	element.HiddenDate = comment.Date.Format(global.TIME_ISO8601)
+	element.Replies = ch.createCommentElementList(comment.Replies)
+
+	return element
+}
+
+func (ch CommentHandler) validationMessage(tag string, err error, request interface{}) []string {
+	var messages []string
+	cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+
+	valError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{cerr.FriendlyMessageWithTag()}
+	}
+
+	for _, elem := range valError {
+		fieldName := elem.Field()
+		field, _ := reflect.TypeOf(request).Elem().FieldByName(fieldName)
+		jsonField, _ := field.Tag.Lookup("json")
+
+		switch elem.Tag() {
+		case "required":
+			msg := fmt.Sprintf(global.ERR_REQUIRED_FORMATTER, jsonField)
+			messages = append(messages, msg)
+			break
+
+		case "max":
+			msg := fmt.Sprintf(global.ERR_MAX_CHAR, jsonField, elem.Param())
+			messages = append(messages, msg)
+			break
+		}
+	}
+
+	return messages
+}
+
+func (ch CommentHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,198 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+type MySqlCommentRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlCommentRepository(db *sql.DB) domain.ICommentRepository {
+	return &MySqlCommentRepository{
+		Db: db,
+	}
+}
+
+func (cr MySqlCommentRepository) InsertComment(comment domain.Comment) (*domain.Comment, error) {
+	if comment.CommentID == "" {
+		comment.CommentID = util.GenerateUUID()
+	}
+	comment.Date = time.Now()
+
+	var parentID interface{}
+	if comment.ParentID != "" {
+		parentID = comment.ParentID
+	}
+
+	/*start create query*/
+	query := sq.Insert("comment").
+		Columns("comment_id", "post_id", "parent_id", "content", "date", "account_id").
+		Values(comment.CommentID, comment.PostID, parentID, comment.Content, comment.Date, comment.AccountID)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICM00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICM01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ICM02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ICM03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ICM04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &comment, nil
+	/*end insert execution*/
+}
+
+// DeleteComment removes the comment, its replies are removed by the
+// foreign key cascade
+func (cr MySqlCommentRepository) DeleteComment(commentID string) error {
+	query := sq.Delete("comment").
+		Where(sq.Eq{"comment_id": commentID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCM00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := cr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCM01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DCM02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DCM03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DCM04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (cr MySqlCommentRepository) GetComment(filter domain.CommentFilter) (*domain.Comment, error) {
+	sqlString, args, err := cr.selectQuery(filter).ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GCM00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := cr.Db.QueryRow(sqlString, args...)
+
+	comment := new(domain.Comment)
+	err = cr.scanComment(row, comment)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GCM01", err, global.FRIENDLY_COMMENT_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return comment, nil
+}
+
+func (cr MySqlCommentRepository) CommentList(filter domain.CommentFilter) ([]domain.Comment, error) {
+	query := cr.selectQuery(filter).
+		OrderBy("comment.date ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("CML00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := cr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("CML01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var commentList []domain.Comment
+	for rows.Next() {
+		var comment domain.Comment
+		err = cr.scanComment(rows, &comment)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("CML02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		commentList = append(commentList, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("CML03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return commentList, nil
+}
+
+func (cr MySqlCommentRepository) selectQuery(filter domain.CommentFilter) sq.SelectBuilder {
+	query := sq.Select(`comment.comment_id, comment.post_id, IFNULL(comment.parent_id, ''), comment.content,
+		comment.date, comment.account_id, IFNULL(profile.username, ''), IFNULL(profile.full_name, '')`).
+		From("comment").
+		LeftJoin("profile ON profile.account_id = comment.account_id")
+
+	if filter.CommentID != "" {
+		query = query.Where(sq.Eq{"comment.comment_id": filter.CommentID})
+	}
+
+	if filter.PostID != "" {
+		query = query.Where(sq.Eq{"comment.post_id": filter.PostID})
+	}
+
+	return query
+}
+
+func (cr MySqlCommentRepository) scanComment(row interface{ Scan(...interface{}) error }, comment *domain.Comment) error {
+	return row.Scan(
+		&comment.CommentID,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.Content,
+		&comment.Date,
+		&comment.AccountID,
+		&comment.Author.Username,
+		&comment.Author.FullName,
+	)
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type CommentUsecase struct {
+	commentRepo domain.ICommentRepository
+	postRepo    domain.IPostRepository
+	notifier    domain.INotifier
+}
+
+func NewCommentUsecase(commentRepository domain.ICommentRepository,
+	postRepository domain.IPostRepository,
+	notifier domain.INotifier) domain.ICommentUsecase {
+	return &CommentUsecase{
+		commentRepo: commentRepository,
+		postRepo:    postRepository,
+		notifier:    notifier,
+	}
+}
+
+func (uc CommentUsecase) InsertComment(comment domain.Comment) (*domain.Comment, error) {
+	post, err := uc.getVisiblePost(comment.PostID, comment.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	//replies notify the parent comment author instead of the post owner
+	notification := domain.Notification{
+		AccountID: post.AccountID,
+		ActorID:   comment.AccountID,
+		Type:      domain.NOTIFICATION_COMMENT,
+		PostID:    comment.PostID,
+	}
+
+	if comment.ParentID != "" {
+		parent, err := uc.getComment(comment.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.PostID != comment.PostID {
+			errMsg := fmt.Sprintf("parent comment %s belongs to post %s", parent.CommentID, parent.PostID)
+			cerr := cerror.NewAndPrintWithTag("ICU00", errors.New(errMsg), global.FRIENDLY_INVALID_PARENT_COMMENT)
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+			return nil, cerr
+		}
+
+		notification.AccountID = parent.AccountID
+		notification.Type = domain.NOTIFICATION_REPLY
+	}
+
+	storedComment, err := uc.commentRepo.InsertComment(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.notifier.Notify(notification)
+	return storedComment, nil
+}
+
+// DeleteComment allows both the comment author and the owner of the
+// commented post to remove a comment
+func (uc CommentUsecase) DeleteComment(commentID, accountID string) error {
+	comment, err := uc.getComment(commentID)
+	if err != nil {
+		return err
+	}
+
+	if comment.AccountID != accountID {
+		post, err := uc.postRepo.GetPost(domain.PostFilter{PostID: comment.PostID})
+		if err != nil {
+			return err
+		}
+
+		if post.AccountID != accountID {
+			errMsg := fmt.Sprintf("account %s is not allowed to delete comment %s", accountID, commentID)
+			cerr := cerror.NewAndPrintWithTag("DCU00", errors.New(errMsg), global.FRIENDLY_COMMENT_NOT_FOUND)
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return cerr
+		}
+	}
+
+	return uc.commentRepo.DeleteComment(commentID)
+}
+
+// CommentList returns the top level comments of the post, replies are nested
+// under their parent
+func (uc CommentUsecase) CommentList(postID, accountID string) ([]domain.Comment, error) {
+	_, err := uc.getVisiblePost(postID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	commentList, err := uc.commentRepo.CommentList(domain.CommentFilter{PostID: postID})
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]domain.Comment)
+	for _, comment := range commentList {
+		children[comment.ParentID] = append(children[comment.ParentID], comment)
+	}
+
+	return uc.buildThread(children, ""), nil
+}
+
+func (uc CommentUsecase) buildThread(children map[string][]domain.Comment, parentID string) []domain.Comment {
+	thread := []domain.Comment{}
+	for _, comment := range children[parentID] {
+		comment.Replies = uc.buildThread(children, comment.CommentID)
+		thread = append(thread, comment)
+	}
+
+	return thread
+}
+
+// getVisiblePost returns the post only when accountID is allowed to see it,
+// hidden posts are reported as not found
+func (uc CommentUsecase) getVisiblePost(postID, accountID string) (*domain.Post, error) {
+	filter := domain.PostFilter{PostID: postID, ViewerID: accountID}
+	post, err := uc.postRepo.GetPost(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (uc CommentUsecase) getComment(commentID string) (*domain.Comment, error) {
+	return uc.commentRepo.GetComment(domain.CommentFilter{CommentID: commentID})
+}
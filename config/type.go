@@ -5,9 +5,19 @@ type Configuration struct {
 	SMTP              SMTP
 	Host              string
 	FEHost            string
+	TrustedProxies    []string
 	EmailVerification EmailVerificationConfig
 	ResetPassword     ResetPasswordConfig
+	JournalInvitation JournalInvitationConfig
+	MagicLink         MagicLinkConfig
+	LoginLockout      LoginLockoutConfig
+	TwoFactor         TwoFactorConfig
+	WebAuthn          WebAuthnConfig
+	OIDCProviders     []OIDCProviderConfig
+	PasswordHash      PasswordHashConfig
+	PasswordPolicy    PasswordPolicyConfig
 	Redis             RedisConfig
+	JWT               JWTConfig
 }
 
 type DBConfig struct {
@@ -34,8 +44,73 @@ type ResetPasswordConfig struct {
 	Subject string
 }
 
+type JournalInvitationConfig struct {
+	Subject string
+}
+
+type MagicLinkConfig struct {
+	Subject string
+}
+
+type LoginLockoutConfig struct {
+	Subject string
+}
+
+// TwoFactorConfig Issuer is the name authenticator apps show next to the code
+type TwoFactorConfig struct {
+	Issuer string
+}
+
+// WebAuthnConfig RPID is the domain passkeys are bound to and Origin is the
+// exact origin of the frontend, e.g. https://example.com
+type WebAuthnConfig struct {
+	RPID   string
+	RPName string
+	Origin string
+}
+
+// PasswordHashConfig tunes argon2id, Memory is in KiB. Zero values use the
+// defaults and hashes with other parameters are upgraded on login
+type PasswordHashConfig struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// PasswordPolicyConfig MinStrength is a zxcvbn score from 0 to 4 and
+// BreachedPasswordPath is a directory of Pwned Passwords range files,
+// the breached check is skipped when it is empty
+type PasswordPolicyConfig struct {
+	MinLength            int
+	MinStrength          int
+	BreachedPasswordPath string
+}
+
+// OIDCProviderConfig Name identifies the provider in the login request,
+// endpoints are read from the discovery document of Issuer
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 type RedisConfig struct {
 	Host     string
 	Port     int
 	Password string
 }
+
+// JWTConfig KeyPath is a directory of <kid>.pem RSA or Ed25519 keys, public
+// only files keep verifying tokens of a retired key. SigningKeyID names the
+// private key new tokens are signed with
+// JWTConfig Issuer defaults to Host, Audience is the aud of access tokens
+// and defaults to Issuer
+type JWTConfig struct {
+	KeyPath      string
+	SigningKeyID string
+	Issuer       string
+	Audience     string
+}
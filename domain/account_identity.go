@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// AccountIdentity links an account to the subject of an OpenID Connect provider
+type AccountIdentity struct {
+	Provider  string
+	Subject   string
+	AccountID string
+	CreatedAt time.Time
+}
+
+type IAccountIdentityRepository interface {
+	GetIdentity(provider, subject string) (*AccountIdentity, error)
+	InsertIdentity(identity AccountIdentity) error
+}
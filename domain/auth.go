@@ -1,16 +1,59 @@
 package domain
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pajri/personal-backend/helper"
 )
 
 type IAuthUsecase interface {
-	Login(account Account) (*helper.JWTWrapper, error)
+	//Login returns a challenge token instead of the token pair when the
+	//account has two-factor authentication enabled, and LoginLockedError
+	//after too many failed attempts for the email or client ip address
+	Login(account Account, client ClientInfo) (token *helper.JWTWrapper, challengeToken string, err error)
+
+	//VerifyTwoFactor and DisableTwoFactor return LoginLockedError after too
+	//many wrong codes for the account
+	VerifyTwoFactor(challengeToken, code string, client ClientInfo) (*helper.JWTWrapper, error)
 	SignUp(account Account, profile Profile) (*Account, *Profile, error)
 	VerifyEmail(token string) error
 	ResetPassword(email string) error
 	ChangePassword(token, password string) error
-	RefreshToken(refreshToken string) (*helper.JWTWrapper, error)
+	RefreshToken(refreshToken string, client ClientInfo) (*helper.JWTWrapper, error)
 	SignOut(accessToken, refreshToken *jwt.Token) error
+	SetupTwoFactor(accountID string) (*TwoFactorSetup, error)
+	EnableTwoFactor(accountID, code string) (recoveryCodes []string, err error)
+	DisableTwoFactor(accountID, password, code string) error
+	BeginPasskeyRegistration(accountID string) (*PasskeyCreationOptions, error)
+	FinishPasskeyRegistration(accountID string, registration PasskeyRegistration) (*Passkey, error)
+	BeginPasskeyLogin() (*PasskeyRequestOptions, error)
+	FinishPasskeyLogin(assertion PasskeyAssertion, client ClientInfo) (*helper.JWTWrapper, error)
+	PasskeyList(accountID string) ([]Passkey, error)
+	RenamePasskey(credentialID []byte, accountID, name string) error
+	RevokePasskey(credentialID []byte, accountID string) error
+	BeginOIDCLogin(provider string) (authorizationURL, state string, err error)
+
+	//FinishOIDCLogin returns a challenge token like Login when the account
+	//has two-factor authentication enabled, browserState is the state
+	//BeginOIDCLogin returned to the same browser
+	FinishOIDCLogin(code, state, browserState string, client ClientInfo) (token *helper.JWTWrapper, challengeToken string, err error)
+	SendMagicLink(email, clientIP string) error
+	LoginWithMagicLink(token string, client ClientInfo) (tokenPair *helper.JWTWrapper, challengeToken string, err error)
+	SessionList(accountID string) ([]Session, error)
+
+	//RevokeSession and RevokeAllSessions sign devices out, their access
+	//tokens stop working right away
+	RevokeSession(sessionID, accountID string) error
+	RevokeAllSessions(accountID string) error
+}
+
+// LoginLockedError RetryAfter is how long the login stays locked
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e LoginLockedError) Error() string {
+	return fmt.Sprintf("login is locked for %s", e.RetryAfter)
 }
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+type Collection struct {
+	CollectionID  string    `json:"collection_id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CoverImageURL string    `json:"cover_image_url"`
+	PostCount     int       `json:"post_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastUpdated   time.Time `json:"last_updated"`
+	AccountID     string    `json:"-"`
+}
+
+type ICollectionRepository interface {
+	InsertCollection(collection Collection) (*Collection, error)
+	UpdateCollection(collection Collection) error
+	DeleteCollection(collectionID, accountID string) error
+	GetCollection(filter CollectionFilter) (*Collection, error)
+	CollectionList(filter CollectionFilter) ([]Collection, error)
+	AddPost(collectionID, postID string) error
+	RemovePost(collectionID, postID string) error
+}
+
+type ICollectionUsecase interface {
+	InsertCollection(collection Collection) (*Collection, error)
+	UpdateCollection(collection Collection) error
+	DeleteCollection(collectionID, accountID string) error
+	CollectionList(accountID string) ([]Collection, error)
+	AddPost(collectionID, postID, accountID string) error
+	RemovePost(collectionID, postID, accountID string) error
+}
+
+type CollectionFilter struct {
+	CollectionID string
+	AccountID    string
+	PostID       string
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+type Comment struct {
+	CommentID string    `json:"comment_id"`
+	PostID    string    `json:"post_id"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Content   string    `json:"content"`
+	Date      time.Time `json:"date"`
+	AccountID string    `json:"-"`
+
+	Author  Profile   `json:"-"`
+	Replies []Comment `json:"-"`
+}
+
+type ICommentRepository interface {
+	InsertComment(comment Comment) (*Comment, error)
+	DeleteComment(commentID string) error
+	GetComment(filter CommentFilter) (*Comment, error)
+	CommentList(filter CommentFilter) ([]Comment, error)
+}
+
+type ICommentUsecase interface {
+	InsertComment(comment Comment) (*Comment, error)
+	DeleteComment(commentID, accountID string) error
+	CommentList(postID, accountID string) ([]Comment, error)
+}
+
+type CommentFilter struct {
+	CommentID string
+	PostID    string
+}
@@ -0,0 +1,49 @@
+package domain
+
+const (
+	EVENT_POST_CREATED = "post_created"
+	EVENT_POST_UPDATED = "post_updated"
+	EVENT_POST_DELETED = "post_deleted"
+	EVENT_NOTIFICATION = "notification"
+)
+
+// Event is pushed to the connected clients of an account, Data is encoded
+// as json
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+type PostEvent struct {
+	PostID    string `json:"post_id"`
+	JournalID string `json:"journal_id,omitempty"`
+}
+
+// IEventPublisher is used by usecases to push events, a failed publish
+// never fails the action that caused it
+type IEventPublisher interface {
+	Publish(event Event, accountIDs ...string)
+}
+
+// StreamTicket lets an EventSource connect without the access token in the
+// url, AccessUUID and ExpiresAt are those of the token it was issued for
+type StreamTicket struct {
+	AccountID  string `json:"account_id"`
+	AccessUUID string `json:"access_uuid"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
+
+type IStreamUsecase interface {
+	IEventPublisher
+
+	//Subscribe returns the events of the account until cancel is called
+	Subscribe(accountID string) (events <-chan Event, cancel func(), err error)
+
+	//CreateTicket returns a short lived ticket that UseTicket accepts once
+	CreateTicket(ticket StreamTicket) (string, error)
+	UseTicket(ticket string) (*StreamTicket, error)
+
+	//IsTicketActive is false once the access token of the ticket is
+	//expired, signed out or revoked
+	IsTicketActive(ticket StreamTicket) bool
+}
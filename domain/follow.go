@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+const (
+	FOLLOW_STATUS_PENDING  = "pending"
+	FOLLOW_STATUS_ACCEPTED = "accepted"
+)
+
+type Follow struct {
+	FollowerID string    `json:"-"`
+	FolloweeID string    `json:"-"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	//Profile is the other side of the relation, the follower when listing
+	//followers and the followee when listing followed accounts
+	Profile Profile `json:"-"`
+}
+
+type FeedItem struct {
+	Post   Post
+	Author Profile
+}
+
+type IFollowRepository interface {
+	InsertFollow(follow Follow) error
+	UpdateStatus(followerID, followeeID, status string) error
+	DeleteFollow(followerID, followeeID string) error
+	GetFollow(followerID, followeeID string) (*Follow, error)
+	FollowerList(filter FollowFilter) ([]Follow, error)
+	FollowingList(filter FollowFilter) ([]Follow, error)
+}
+
+type IFollowUsecase interface {
+	Follow(accountID, username string) (*Follow, error)
+	Unfollow(accountID, username string) error
+	AcceptRequest(accountID, username string) error
+	RejectRequest(accountID, username string) error
+	FollowerList(accountID, status string) ([]Follow, error)
+	FollowingList(accountID string) ([]Follow, error)
+	Feed(accountID string, limit uint64, date time.Time) ([]FeedItem, error)
+}
+
+type FollowFilter struct {
+	AccountID string
+	Status    string
+}
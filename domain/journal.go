@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+const (
+	JOURNAL_ROLE_OWNER  = "owner"
+	JOURNAL_ROLE_EDITOR = "editor"
+	JOURNAL_ROLE_VIEWER = "viewer"
+)
+
+type Journal struct {
+	JournalID   string    `json:"journal_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	//Role is the role of the requesting account in the journal
+	Role string `json:"role,omitempty"`
+}
+
+type JournalMember struct {
+	JournalID string    `json:"-"`
+	AccountID string    `json:"-"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	Profile   Profile   `json:"-"`
+}
+
+type JournalInvitation struct {
+	InvitationID string    `json:"invitation_id"`
+	JournalID    string    `json:"journal_id"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	Token        string    `json:"-"`
+	TokenHash    string    `json:"-"`
+	InvitedBy    string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type IJournalRepository interface {
+	InsertJournal(journal Journal, ownerID string) (*Journal, error)
+	GetJournal(filter JournalFilter) (*Journal, error)
+	JournalList(filter JournalFilter) ([]Journal, error)
+	DeleteJournal(journalID string) error
+	GetMember(journalID, accountID string) (*JournalMember, error)
+	MemberList(journalID string) ([]JournalMember, error)
+	UpdateMemberRole(journalID, accountID, role string) error
+	DeleteMember(journalID, accountID string) error
+	InsertInvitation(invitation JournalInvitation) (*JournalInvitation, error)
+	GetInvitation(tokenHash string) (*JournalInvitation, error)
+	AcceptInvitation(invitation JournalInvitation, accountID string) error
+}
+
+type IJournalUsecase interface {
+	CreateJournal(journal Journal, accountID string) (*Journal, error)
+	JournalList(accountID string) ([]Journal, error)
+	DeleteJournal(journalID, accountID string) error
+	InviteMember(journalID, accountID, email, role string) (*JournalInvitation, error)
+	AcceptInvitation(token, accountID string) (*Journal, error)
+	MemberList(journalID, accountID string) ([]JournalMember, error)
+	UpdateMemberRole(journalID, accountID, username, role string) error
+	RemoveMember(journalID, accountID, username string) error
+}
+
+// JournalFilter selects journals, AccountID limits the result to journals
+// the account is a member of
+type JournalFilter struct {
+	JournalID string
+	AccountID string
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+const (
+	NOTIFICATION_FOLLOW          = "follow"
+	NOTIFICATION_FOLLOW_REQUEST  = "follow_request"
+	NOTIFICATION_COMMENT         = "comment"
+	NOTIFICATION_REPLY           = "reply"
+	NOTIFICATION_REACTION        = "reaction"
+	NOTIFICATION_SHARE_LINK_VIEW = "share_link_view"
+	NOTIFICATION_MEMORY          = "memory"
+)
+
+type Notification struct {
+	NotificationID string    `json:"notification_id"`
+	AccountID      string    `json:"-"`
+	ActorID        string    `json:"-"`
+	Type           string    `json:"type"`
+	PostID         string    `json:"post_id,omitempty"`
+	IsRead         bool      `json:"is_read"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	//Actor is the profile of the account that caused the event, empty for
+	//events without an actor like memories and share link views
+	Actor Profile `json:"-"`
+}
+
+// INotifier is used by usecases to emit events, a failed notification
+// never fails the action that caused it
+type INotifier interface {
+	Notify(notification Notification)
+}
+
+type INotificationRepository interface {
+	InsertNotification(notification Notification) error
+	NotificationList(filter NotificationFilter) ([]Notification, error)
+	UnreadCount(accountID string) (int, error)
+	MarkRead(notificationID, accountID string) error
+	MarkAllRead(accountID string) error
+	DeleteNotificationBefore(date time.Time) (int64, error)
+}
+
+type INotificationUsecase interface {
+	INotifier
+	NotificationList(filter NotificationFilter) ([]Notification, int, error)
+	MarkRead(notificationID, accountID string) error
+	MarkAllRead(accountID string) error
+	PruneNotification() error
+	NotifyMemory(date time.Time) error
+}
+
+type NotificationFilter struct {
+	AccountID string
+	Date      time.Time
+	Limit     uint64
+}
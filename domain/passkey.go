@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+type Passkey struct {
+	CredentialID []byte
+	AccountID    string
+	Name         string
+	PublicKey    []byte
+	SignCount    uint32
+	Transports   []string
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+}
+
+// PasskeyRegistration is the response of navigator.credentials.create
+type PasskeyRegistration struct {
+	Name              string
+	ClientDataJSON    []byte
+	AttestationObject []byte
+	Transports        []string
+}
+
+// PasskeyAssertion is the response of navigator.credentials.get
+type PasskeyAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+// PasskeyCreationOptions is passed to navigator.credentials.create as
+// publicKey, binary values are base64url encoded
+type PasskeyCreationOptions struct {
+	Challenge              string                       `json:"challenge"`
+	RP                     PasskeyRP                    `json:"rp"`
+	User                   PasskeyUser                  `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParameter `json:"pubKeyCredParams"`
+	Timeout                int64                        `json:"timeout"`
+	Attestation            string                       `json:"attestation"`
+	AuthenticatorSelection PasskeySelection             `json:"authenticatorSelection"`
+	ExcludeCredentials     []PasskeyDescriptor          `json:"excludeCredentials"`
+}
+
+// PasskeyRequestOptions is passed to navigator.credentials.get as publicKey,
+// no credential is listed so the authenticator offers its discoverable ones
+type PasskeyRequestOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int64  `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+type PasskeyRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PasskeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type PasskeyCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type PasskeySelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+type PasskeyDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type IPasskeyRepository interface {
+	InsertPasskey(passkey Passkey) error
+	GetPasskey(credentialID []byte) (*Passkey, error)
+	PasskeyList(accountID string) ([]Passkey, error)
+	UpdateName(credentialID []byte, accountID, name string) error
+	DeletePasskey(credentialID []byte, accountID string) error
+
+	//UpdateSignCount records a successful assertion
+	UpdateSignCount(credentialID []byte, signCount uint32) error
+}
@@ -4,32 +4,119 @@ import (
 	"time"
 )
 
+const (
+	MOOD_NONE  = 0
+	MOOD_AWFUL = 1
+	MOOD_BAD   = 2
+	MOOD_OKAY  = 3
+	MOOD_GOOD  = 4
+	MOOD_GREAT = 5
+)
+
+const (
+	VISIBILITY_PRIVATE   = "private"
+	VISIBILITY_UNLISTED  = "unlisted"
+	VISIBILITY_FOLLOWERS = "followers"
+	VISIBILITY_PUBLIC    = "public"
+)
+
+var MoodEmoji = map[int]string{
+	MOOD_AWFUL: "😞",
+	MOOD_BAD:   "🙁",
+	MOOD_OKAY:  "😐",
+	MOOD_GOOD:  "🙂",
+	MOOD_GREAT: "😄",
+}
+
 type Post struct {
-	PostID      string    `json:"post_id"`
-	Content     string    `json:"content"`
-	ImageURL    string    `json:"image_url"`
-	Date        time.Time `json:"date"`
-	LastUpdated time.Time `json:"last_updated"`
-	AccountID   string    `json:"account_id"`
-	Account     Account   `json:"-"`
+	PostID       string            `json:"post_id"`
+	Content      string            `json:"content"`
+	ContentHTML  string            `json:"content_html"`
+	ImageURL     string            `json:"image_url"`
+	Mood         int               `json:"mood"`
+	PlaceName    string            `json:"place_name"`
+	Latitude     *float64          `json:"latitude"`
+	Longitude    *float64          `json:"longitude"`
+	Weather      string            `json:"weather"`
+	Geohash      string            `json:"-"`
+	IsFavorite   bool              `json:"is_favorite"`
+	IsPinned     bool              `json:"is_pinned"`
+	Visibility   string            `json:"visibility"`
+	CommentCount int               `json:"comment_count"`
+	Reactions    []ReactionSummary `json:"reactions"`
+	Date         time.Time         `json:"date"`
+	LastUpdated  time.Time         `json:"last_updated"`
+	AccountID    string            `json:"account_id"`
+	JournalID    string            `json:"journal_id"`
+	Account      Account           `json:"-"`
 }
 
 type IPostRepository interface {
 	InsertPost(post Post) (*Post, error)
-	DeletePost(postID, accountID string) error
+	DeletePost(postID string) error
+	UpdateFavorite(postID string, isFavorite bool) error
+	UpdatePinned(postID string, isPinned bool) error
+	UpdateVisibility(postID, visibility string) error
 	PostList(filter PostFilter) ([]Post, error)
 	GetPost(filter PostFilter) (*Post, error)
+	PostClusterList(filter PostFilter, precision int) ([]PostCluster, error)
+
+	//MemoryList returns the latest post of every account written on the
+	//same day and month as date in previous years, only AccountID and
+	//PostID are set
+	MemoryList(date time.Time) ([]Post, error)
 }
 
 type IPostUsecase interface {
 	InsertPost(post Post) (*Post, error)
 	DeletePost(postID, accountID string) error
-	PostListing(accountId string, limit uint64, date time.Time) ([]Post, error)
+	SetFavorite(postID, accountID string, isFavorite bool) error
+	SetPinned(postID, accountID string, isPinned bool) error
+	SetVisibility(postID, accountID, visibility string) error
+	PostListing(filter PostFilter) ([]Post, error)
+	PublicPostListing(username string, limit uint64, date time.Time) ([]Post, error)
+	GetPublicPost(username, postID string) (*Post, error)
+	PostMap(filter PostFilter, zoom int) ([]PostCluster, error)
 }
 
 type PostFilter struct {
+	PostID       string
+	AccountID    string
+	CollectionID string
+	JournalID    string
+	Date         time.Time
+	Limit        uint64
+	Mood         int
+	BoundingBox  *BoundingBox
+	FavoriteOnly bool
+	IsPinned     *bool
+	Visibility   []string
+	AccountIDs   []string
+	FollowerID   string
+
+	//ViewerID limits the result to posts the account is allowed to see
+	ViewerID string
+
+	//RestrictedBy excludes posts of accounts blocked or muted by the account
+	RestrictedBy string
+}
+
+// BoundingBox is a geographic area, MinLon may be greater than MaxLon
+// when the box crosses the antimeridian
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// PostCluster groups posts sharing a geohash cell, PostID and ImageURL
+// belong to the representative post of the cell
+type PostCluster struct {
+	Geohash   string
+	Count     int
+	Latitude  float64
+	Longitude float64
 	PostID    string
-	AccountID string
-	Date      time.Time
-	Limit     uint64
+	ImageURL  string
 }
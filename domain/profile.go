@@ -3,6 +3,8 @@ package domain
 type Profile struct {
 	ProfileID string  `json:"-"`
 	FullName  string  `json:"full_name"`
+	Username  string  `json:"username"`
+	IsPrivate bool    `json:"is_private"`
 	AccountID string  `json:"-"`
 	Account   Account `json:"-"`
 }
@@ -11,14 +13,22 @@ type IProfileRepository interface {
 	InsertProfile(profile Profile) error
 	GetProfile(filter ProfileFilter) (*Profile, error)
 	UpdateFullName(profile Profile) error
+	UpdateUsername(profile Profile) error
+	UpdatePrivacy(profile Profile) error
 }
 
 type IProfileUsecase interface {
 	GetProfile(profile Profile) (*Profile, error)
 	UpdateProfile(profile Profile) error
+	UpdatePrivacy(profile Profile) error
+	GetPublicProfile(username string) (*Profile, error)
+	Restrict(accountID, username, restrictionType string) error
+	Unrestrict(accountID, username, restrictionType string) error
+	RestrictionList(accountID, restrictionType string) ([]Restriction, error)
 }
 
 type ProfileFilter struct {
 	ProfileID string
 	AccountID string
+	Username  string
 }
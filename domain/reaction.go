@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+const (
+	REACTION_HEART = "heart"
+	REACTION_LAUGH = "laugh"
+	REACTION_WOW   = "wow"
+	REACTION_SAD   = "sad"
+	REACTION_CLAP  = "clap"
+	REACTION_FIRE  = "fire"
+)
+
+var ReactionEmoji = map[string]string{
+	REACTION_HEART: "❤️",
+	REACTION_LAUGH: "😂",
+	REACTION_WOW:   "😮",
+	REACTION_SAD:   "😢",
+	REACTION_CLAP:  "👏",
+	REACTION_FIRE:  "🔥",
+}
+
+type Reaction struct {
+	PostID    string    `json:"post_id"`
+	AccountID string    `json:"-"`
+	Reaction  string    `json:"reaction"`
+	Date      time.Time `json:"date"`
+}
+
+// ReactionSummary is the number of accounts that reacted to a post
+// with the same emoji
+type ReactionSummary struct {
+	Reaction    string `json:"reaction"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+type IReactionRepository interface {
+	InsertReaction(reaction Reaction) (inserted bool, err error)
+	DeleteReaction(reaction Reaction) error
+	ReactionSummaryList(postIDs []string, accountID string) (map[string][]ReactionSummary, error)
+}
+
+type IReactionUsecase interface {
+	SetReaction(reaction Reaction, reacted bool) ([]ReactionSummary, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+const (
+	RESTRICTION_BLOCK = "block"
+	RESTRICTION_MUTE  = "mute"
+)
+
+// Restriction is a block or mute placed by AccountID on TargetID
+type Restriction struct {
+	AccountID string    `json:"-"`
+	TargetID  string    `json:"-"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+
+	//Profile is the profile of the restricted account
+	Profile Profile `json:"-"`
+}
+
+type IRestrictionRepository interface {
+	InsertRestriction(restriction Restriction) error
+	DeleteRestriction(restriction Restriction) error
+	HasRestriction(accountID, targetID, restrictionType string) (bool, error)
+	RestrictionList(filter RestrictionFilter) ([]Restriction, error)
+}
+
+type RestrictionFilter struct {
+	AccountID string
+	Type      string
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Session is one signed in device, AccessUUID and RefreshUUID are the redis
+// keys of the tokens it currently holds
+type Session struct {
+	SessionID   string
+	AccountID   string
+	DeviceName  string
+	IPAddress   string
+	UserAgent   string
+	AccessUUID  string
+	RefreshUUID string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+}
+
+// ClientInfo describes the device a request comes from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type ISessionRepository interface {
+	InsertSession(session Session) error
+	GetSession(sessionID string) (*Session, error)
+
+	//SessionList only returns sessions whose refresh token has not expired
+	SessionList(accountID string) ([]Session, error)
+
+	//UpdateSession stores the tokens issued by a refresh
+	UpdateSession(session Session) error
+	DeleteSession(sessionID string) error
+	DeleteExpiredSessions(accountID string) error
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type ShareLink struct {
+	ShareLinkID  string     `json:"share_link_id"`
+	PostID       string     `json:"post_id"`
+	AccountID    string     `json:"-"`
+	Token        string     `json:"-"`
+	TokenHash    string     `json:"-"`
+	PasswordHash string     `json:"-"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type IShareLinkRepository interface {
+	InsertShareLink(shareLink ShareLink) (*ShareLink, error)
+	GetShareLink(filter ShareLinkFilter) (*ShareLink, error)
+	ShareLinkList(filter ShareLinkFilter) ([]ShareLink, error)
+	DeleteShareLink(shareLinkID, accountID string) error
+}
+
+type IShareLinkUsecase interface {
+	CreateShareLink(shareLink ShareLink, password string) (*ShareLink, error)
+	ShareLinkList(accountID, postID string) ([]ShareLink, error)
+	RevokeShareLink(shareLinkID, accountID string) error
+	//GetSharedPost returns ShareLinkLockedError after too many wrong
+	//passwords for the share link or client ip address
+	GetSharedPost(token, password, clientIP string) (*Post, error)
+}
+
+// ShareLinkLockedError RetryAfter is how long the share link stays locked
+type ShareLinkLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e ShareLinkLockedError) Error() string {
+	return fmt.Sprintf("share link is locked for %s", e.RetryAfter)
+}
+
+type ShareLinkFilter struct {
+	ShareLinkID string
+	AccountID   string
+	PostID      string
+	TokenHash   string
+}
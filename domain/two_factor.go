@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+type TwoFactor struct {
+	AccountID    string
+	Secret       string
+	IsEnabled    bool
+	LastUsedStep int64
+	CreatedAt    time.Time
+	EnabledAt    *time.Time
+}
+
+// TwoFactorSetup is returned on enrollment, the provisioning uri is shown to
+// the user as a qr code
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type ITwoFactorRepository interface {
+	GetTwoFactor(accountID string) (*TwoFactor, error)
+
+	//SaveSecret starts an enrollment, replacing any enrollment that has not
+	//been confirmed
+	SaveSecret(twoFactor TwoFactor) error
+
+	//EnableTwoFactor confirms the enrollment and replaces the recovery codes
+	EnableTwoFactor(accountID string, recoveryCodeHashes []string) error
+	DeleteTwoFactor(accountID string) error
+
+	//UseStep records the time step of an accepted code, used is false when
+	//the step or a later one has already been used
+	UseStep(accountID string, step int64) (used bool, err error)
+
+	//UseRecoveryCode consumes the recovery code, used is false when the code
+	//does not exist or has already been consumed
+	UseRecoveryCode(accountID, codeHash string) (used bool, err error)
+}
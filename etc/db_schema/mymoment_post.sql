@@ -25,13 +25,31 @@ DROP TABLE IF EXISTS `post`;
 CREATE TABLE `post` (
   `post_id` varchar(255) NOT NULL,
   `content` text,
+  `content_html` text,
   `image_url` text,
+  `mood` tinyint(4) DEFAULT NULL,
+  `place_name` varchar(255) DEFAULT NULL,
+  `latitude` decimal(9,6) DEFAULT NULL,
+  `longitude` decimal(9,6) DEFAULT NULL,
+  `weather` varchar(100) DEFAULT NULL,
+  `geohash` char(12) DEFAULT NULL,
+  `is_favorite` tinyint(1) NOT NULL DEFAULT '0',
+  `is_pinned` tinyint(1) NOT NULL DEFAULT '0',
+  `visibility` varchar(10) NOT NULL DEFAULT 'private',
   `date` datetime DEFAULT NULL,
-  `last_updated` varchar(45) DEFAULT NULL,
+  `last_updated` datetime DEFAULT NULL,
   `account_id` varchar(45) DEFAULT NULL,
+  `journal_id` varchar(255) DEFAULT NULL,
   PRIMARY KEY (`post_id`),
   KEY `fk_account_account_id_idx` (`account_id`),
-  CONSTRAINT `fk_account_account_id` FOREIGN KEY (`account_id`) REFERENCES `account` (`account_id`)
+  KEY `idx_post_account_mood` (`account_id`,`mood`),
+  KEY `idx_post_latitude_longitude` (`latitude`,`longitude`),
+  KEY `idx_post_account_geohash` (`account_id`,`geohash`),
+  KEY `idx_post_account_pinned_date` (`account_id`,`is_pinned`,`date`),
+  KEY `idx_post_account_visibility_date` (`account_id`,`visibility`,`date`),
+  KEY `idx_post_journal_date` (`journal_id`,`date`),
+  CONSTRAINT `fk_account_account_id` FOREIGN KEY (`account_id`) REFERENCES `account` (`account_id`),
+  CONSTRAINT `fk_post_journal` FOREIGN KEY (`journal_id`) REFERENCES `journal` (`journal_id`) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 /*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
@@ -25,8 +25,11 @@ DROP TABLE IF EXISTS `profile`;
 CREATE TABLE `profile` (
   `profile_id` varchar(255) NOT NULL,
   `full_name` text,
+  `username` varchar(30) DEFAULT NULL,
+  `is_private` tinyint(1) NOT NULL DEFAULT '0',
   `account_id` varchar(255) DEFAULT NULL,
   PRIMARY KEY (`profile_id`),
+  UNIQUE KEY `username_UNIQUE` (`username`),
   KEY `fk_profile_account_idx` (`account_id`),
   CONSTRAINT `fk_profile_account` FOREIGN KEY (`account_id`) REFERENCES `account` (`account_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,281 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type FollowRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type FollowResponse struct {
+	Message []string `json:"message"`
+	Status  string   `json:"status,omitempty"`
+}
+
+type FollowListRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending accepted"`
+}
+
+type FollowListResponse struct {
+	Message    string          `json:"message"`
+	FollowList []FollowElement `json:"follow_list"`
+}
+
+type FollowElement struct {
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+type FeedRequest struct {
+	Date  time.Time `form:"date"`
+	Limit uint64    `form:"limit"`
+}
+
+type FeedResponse struct {
+	Message  string        `json:"message"`
+	PostList []FeedElement `json:"post_list"`
+}
+
+type FeedElement struct {
+	PostID       string                   `json:"post_id"`
+	Author       FeedAuthor               `json:"author"`
+	Content      string                   `json:"content"`
+	ContentHTML  string                   `json:"content_html"`
+	ImageURL     string                   `json:"image_url"`
+	Mood         int                      `json:"mood,omitempty"`
+	MoodEmoji    string                   `json:"mood_emoji,omitempty"`
+	Weather      string                   `json:"weather,omitempty"`
+	Visibility   string                   `json:"visibility"`
+	CommentCount int                      `json:"comment_count"`
+	Reactions    []domain.ReactionSummary `json:"reactions"`
+	Date         string                   `json:"date"`
+	HiddenDate   string                   `json:"hidden_date"`
+}
+
+type FeedAuthor struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+/* #endregion */
+
+type FollowHandler struct {
+	useCase domain.IFollowUsecase
+}
+
+func NewFollowHandler(router *gin.Engine, followUsecase domain.IFollowUsecase) {
+	handler := &FollowHandler{
+		useCase: followUsecase,
+	}
+
+	router.POST("/api/follow", handler.Follow)
+	router.POST("/api/follow/unfollow", handler.Unfollow)
+	router.POST("/api/follow/accept", handler.AcceptRequest)
+	router.POST("/api/follow/reject", handler.RejectRequest)
+	router.GET("/api/follow/followers", handler.FollowerList)
+	router.GET("/api/follow/requests", handler.PendingRequestList)
+	router.GET("/api/follow/following", handler.FollowingList)
+	router.GET("/api/feed", handler.Feed)
+}
+
+func (fh FollowHandler) Follow(c *gin.Context) {
+	var (
+		request   FollowRequest
+		response  FollowResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FLH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	follow, err := fh.useCase.Follow(accountID, request.Username)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(fh.errorStatus(cerr), response)
+		return
+	}
+
+	response.Status = follow.Status
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (fh FollowHandler) Unfollow(c *gin.Context) {
+	fh.handleFollowAction(c, "UFH00", fh.useCase.Unfollow)
+}
+
+func (fh FollowHandler) AcceptRequest(c *gin.Context) {
+	fh.handleFollowAction(c, "AFH00", fh.useCase.AcceptRequest)
+}
+
+func (fh FollowHandler) RejectRequest(c *gin.Context) {
+	fh.handleFollowAction(c, "RFH00", fh.useCase.RejectRequest)
+}
+
+func (fh FollowHandler) FollowerList(c *gin.Context) {
+	var (
+		request   FollowListRequest
+		response  FollowListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FRH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if request.Status == "" {
+		request.Status = domain.FOLLOW_STATUS_ACCEPTED
+	}
+
+	followList, err := fh.useCase.FollowerList(accountID, request.Status)
+	fh.writeFollowList(c, followList, err)
+}
+
+func (fh FollowHandler) PendingRequestList(c *gin.Context) {
+	accountID := c.GetString("account_id")
+
+	followList, err := fh.useCase.FollowerList(accountID, domain.FOLLOW_STATUS_PENDING)
+	fh.writeFollowList(c, followList, err)
+}
+
+func (fh FollowHandler) FollowingList(c *gin.Context) {
+	accountID := c.GetString("account_id")
+
+	followList, err := fh.useCase.FollowingList(accountID)
+	fh.writeFollowList(c, followList, err)
+}
+
+func (fh FollowHandler) Feed(c *gin.Context) {
+	var (
+		request   FeedRequest
+		response  FeedResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FDH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	feed, err := fh.useCase.Feed(accountID, request.Limit, request.Date)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.PostList = []FeedElement{}
+	for _, item := range feed {
+		response.PostList = append(response.PostList, fh.createFeedElement(item))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (fh FollowHandler) handleFollowAction(c *gin.Context, tag string, action func(accountID, username string) error) {
+	var (
+		request   FollowRequest
+		response  FollowResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = action(accountID, request.Username)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(fh.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (fh FollowHandler) writeFollowList(c *gin.Context, followList []domain.Follow, err error) {
+	var response FollowListResponse
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.FollowList = []FollowElement{}
+	for _, follow := range followList {
+		response.FollowList = append(response.FollowList, FollowElement{
+			Username:  follow.Profile.Username,
+			FullName:  follow.Profile.FullName,
+			Status:    follow.Status,
+			CreatedAt: follow.CreatedAt.Format(global.TIME_ISO8601),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// createFeedElement leaves out the location of the post, like the public
+// profile does
+func (fh FollowHandler) createFeedElement(item domain.FeedItem) FeedElement {
+	var element FeedElement
+	element.PostID = item.Post.PostID
+	element.Author.Username = item.Author.Username
+	element.Author.FullName = item.Author.FullName
+	element.Content = item.Post.Content
+	element.ContentHTML = item.Post.ContentHTML
+	element.ImageURL = item.Post.ImageURL
+	element.Mood = item.Post.Mood
+	element.MoodEmoji = domain.MoodEmoji[item.Post.Mood]
+	element.Weather = item.Post.Weather
+	element.Visibility = item.Post.Visibility
+	element.CommentCount = item.Post.CommentCount
+	element.Reactions = item.Post.Reactions
+	if element.Reactions == nil {
+		element.Reactions = []domain.ReactionSummary{}
+	}
+	element.Date = item.Post.Date.Format(global.TIME_FORMAT)
+	element.HiddenDate = item.Post.Date.Format(global.TIME_ISO8601)
+
+	return element
+}
+
+func (fh FollowHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case cerror.TYPE_UNAUTHORIZED:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlFollowRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlFollowRepository(db *sql.DB) domain.IFollowRepository {
+	return &MySqlFollowRepository{
+		Db: db,
+	}
+}
+
+func (fr MySqlFollowRepository) InsertFollow(follow domain.Follow) error {
+	/*start create query*/
+	query := sq.Insert("follow").
+		Columns("follower_id", "followee_id", "status", "created_at").
+		Values(follow.FollowerID, follow.FolloweeID, follow.Status, time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IFR00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := fr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IFR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IFR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		errMySQL, ok := err.(*mysql.MySQLError)
+		if ok && errMySQL.Number == 1062 {
+			cerr := cerror.NewAndPrintWithTag("IFR03", err, global.FRIENDLY_ALREADY_FOLLOWED)
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+			return cerr
+		}
+		return cerror.NewAndPrintWithTag("IFR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IFR05", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (fr MySqlFollowRepository) UpdateStatus(followerID, followeeID, status string) error {
+	query := sq.Update("follow").
+		Set("status", status).
+		Where(sq.Eq{
+			"follower_id": followerID,
+			"followee_id": followeeID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFS00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := fr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFS01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UFS02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UFS03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFS04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (fr MySqlFollowRepository) DeleteFollow(followerID, followeeID string) error {
+	query := sq.Delete("follow").
+		Where(sq.Eq{
+			"follower_id": followerID,
+			"followee_id": followeeID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DFR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := fr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DFR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DFR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DFR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DFR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (fr MySqlFollowRepository) GetFollow(followerID, followeeID string) (*domain.Follow, error) {
+	query := sq.Select("follower_id, followee_id, status, created_at").
+		From("follow").
+		Where(sq.Eq{
+			"follower_id": followerID,
+			"followee_id": followeeID,
+		})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GFR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := fr.Db.QueryRow(sqlString, args...)
+
+	follow := new(domain.Follow)
+	err = row.Scan(&follow.FollowerID, &follow.FolloweeID, &follow.Status, &follow.CreatedAt)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GFR01", err, global.FRIENDLY_FOLLOW_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return follow, nil
+}
+
+func (fr MySqlFollowRepository) FollowerList(filter domain.FollowFilter) ([]domain.Follow, error) {
+	query := fr.listQuery("follower_id").
+		Where(sq.Eq{"follow.followee_id": filter.AccountID})
+
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"follow.status": filter.Status})
+	}
+
+	return fr.queryList("FLR", query)
+}
+
+func (fr MySqlFollowRepository) FollowingList(filter domain.FollowFilter) ([]domain.Follow, error) {
+	query := fr.listQuery("followee_id").
+		Where(sq.Eq{"follow.follower_id": filter.AccountID})
+
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"follow.status": filter.Status})
+	}
+
+	return fr.queryList("FGR", query)
+}
+
+// listQuery joins the profile of the other side of the relation, profileColumn
+// is the follow column pointing to that account
+func (fr MySqlFollowRepository) listQuery(profileColumn string) sq.SelectBuilder {
+	return sq.Select(`follow.follower_id, follow.followee_id, follow.status, follow.created_at,
+		profile.profile_id, profile.full_name, IFNULL(profile.username, ''), profile.is_private, profile.account_id`).
+		From("follow").
+		Join("profile ON profile.account_id = follow." + profileColumn).
+		OrderBy("follow.created_at DESC")
+}
+
+func (fr MySqlFollowRepository) queryList(tag string, query sq.SelectBuilder) ([]domain.Follow, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag(tag+"00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := fr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag(tag+"01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var followList []domain.Follow
+	for rows.Next() {
+		var follow domain.Follow
+		err = rows.Scan(
+			&follow.FollowerID,
+			&follow.FolloweeID,
+			&follow.Status,
+			&follow.CreatedAt,
+			&follow.Profile.ProfileID,
+			&follow.Profile.FullName,
+			&follow.Profile.Username,
+			&follow.Profile.IsPrivate,
+			&follow.Profile.AccountID,
+		)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag(tag+"02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		followList = append(followList, follow)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag(tag+"03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return followList, nil
+}
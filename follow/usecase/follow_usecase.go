@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type FollowUsecase struct {
+	followRepo      domain.IFollowRepository
+	profileRepo     domain.IProfileRepository
+	postRepo        domain.IPostRepository
+	reactionRepo    domain.IReactionRepository
+	restrictionRepo domain.IRestrictionRepository
+	notifier        domain.INotifier
+}
+
+func NewFollowUsecase(followRepository domain.IFollowRepository,
+	profileRepository domain.IProfileRepository,
+	postRepository domain.IPostRepository,
+	reactionRepository domain.IReactionRepository,
+	restrictionRepository domain.IRestrictionRepository,
+	notifier domain.INotifier) domain.IFollowUsecase {
+	return &FollowUsecase{
+		followRepo:      followRepository,
+		profileRepo:     profileRepository,
+		postRepo:        postRepository,
+		reactionRepo:    reactionRepository,
+		restrictionRepo: restrictionRepository,
+		notifier:        notifier,
+	}
+}
+
+func (uc FollowUsecase) Follow(accountID, username string) (*domain.Follow, error) {
+	followee, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if followee.AccountID == accountID {
+		cerr := cerror.NewAndPrintWithTag("FLU00", errors.New("account tried to follow itself"), global.FRIENDLY_FOLLOW_SELF)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	blocked, err := uc.restrictionRepo.HasRestriction(followee.AccountID, accountID, domain.RESTRICTION_BLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	if blocked {
+		cerr := cerror.NewAndPrintWithTag("FLU01", errors.New("account is blocked by followee"), global.FRIENDLY_BLOCKED)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	//private accounts have to accept the request first
+	follow := domain.Follow{
+		FollowerID: accountID,
+		FolloweeID: followee.AccountID,
+		Status:     domain.FOLLOW_STATUS_ACCEPTED,
+		CreatedAt:  time.Now(),
+		Profile:    *followee,
+	}
+	if followee.IsPrivate {
+		follow.Status = domain.FOLLOW_STATUS_PENDING
+	}
+
+	err = uc.followRepo.InsertFollow(follow)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := domain.Notification{
+		AccountID: followee.AccountID,
+		ActorID:   accountID,
+		Type:      domain.NOTIFICATION_FOLLOW,
+	}
+	if follow.Status == domain.FOLLOW_STATUS_PENDING {
+		notification.Type = domain.NOTIFICATION_FOLLOW_REQUEST
+	}
+	uc.notifier.Notify(notification)
+
+	return &follow, nil
+}
+
+func (uc FollowUsecase) Unfollow(accountID, username string) error {
+	followee, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	_, err = uc.followRepo.GetFollow(accountID, followee.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return uc.followRepo.DeleteFollow(accountID, followee.AccountID)
+}
+
+func (uc FollowUsecase) AcceptRequest(accountID, username string) error {
+	follower, err := uc.getPendingRequest(accountID, username)
+	if err != nil {
+		return err
+	}
+
+	return uc.followRepo.UpdateStatus(follower.AccountID, accountID, domain.FOLLOW_STATUS_ACCEPTED)
+}
+
+func (uc FollowUsecase) RejectRequest(accountID, username string) error {
+	follower, err := uc.getPendingRequest(accountID, username)
+	if err != nil {
+		return err
+	}
+
+	return uc.followRepo.DeleteFollow(follower.AccountID, accountID)
+}
+
+func (uc FollowUsecase) FollowerList(accountID, status string) ([]domain.Follow, error) {
+	filter := domain.FollowFilter{AccountID: accountID, Status: status}
+	return uc.followRepo.FollowerList(filter)
+}
+
+func (uc FollowUsecase) FollowingList(accountID string) ([]domain.Follow, error) {
+	filter := domain.FollowFilter{AccountID: accountID}
+	return uc.followRepo.FollowingList(filter)
+}
+
+func (uc FollowUsecase) Feed(accountID string, limit uint64, date time.Time) ([]domain.FeedItem, error) {
+	followFilter := domain.FollowFilter{AccountID: accountID, Status: domain.FOLLOW_STATUS_ACCEPTED}
+	followingList, err := uc.followRepo.FollowingList(followFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(followingList) == 0 {
+		return []domain.FeedItem{}, nil
+	}
+
+	authors := make(map[string]domain.Profile)
+	for _, follow := range followingList {
+		authors[follow.FolloweeID] = follow.Profile
+	}
+
+	var postFilter domain.PostFilter
+	postFilter.FollowerID = accountID
+	postFilter.RestrictedBy = accountID
+	postFilter.Visibility = []string{domain.VISIBILITY_PUBLIC, domain.VISIBILITY_FOLLOWERS}
+	postFilter.Limit = limit
+	postFilter.Date = date
+	postList, err := uc.postRepo.PostList(postFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var postIDs []string
+	for _, post := range postList {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	reactions, err := uc.reactionRepo.ReactionSummaryList(postIDs, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := []domain.FeedItem{}
+	for _, post := range postList {
+		post.Reactions = reactions[post.PostID]
+		feed = append(feed, domain.FeedItem{
+			Post:   post,
+			Author: authors[post.AccountID],
+		})
+	}
+
+	return feed, nil
+}
+
+func (uc FollowUsecase) getPendingRequest(accountID, username string) (*domain.Profile, error) {
+	follower, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	follow, err := uc.followRepo.GetFollow(follower.AccountID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if follow.Status != domain.FOLLOW_STATUS_PENDING {
+		cerr := cerror.NewAndPrintWithTag("GPQ00", errors.New("follow request is not pending"), global.FRIENDLY_FOLLOW_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return nil, cerr
+	}
+
+	return follower, nil
+}
+
+func (uc FollowUsecase) getProfileByUsername(username string) (*domain.Profile, error) {
+	filter := domain.ProfileFilter{Username: username}
+	profile, err := uc.profileRepo.GetProfile(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_PROFILE_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
@@ -5,6 +5,14 @@ const (
 	ERR_DIFFERENT_FORMATTER         = "%s must be the same with %s"
 	ERR_IMAGE_NOT_ALLOWED           = "image type %s is not allowed"
 	ERR_MIN_CHAR                    = "minimum character for %s is %s"
+	ERR_MAX_CHAR                    = "maximum character for %s is %s"
+	ERR_MIN_VALUE                   = "minimum value for %s is %s"
+	ERR_MAX_VALUE                   = "maximum value for %s is %s"
+	ERR_REQUIRED_WITH_FORMATTER     = "%s is required when %s is filled"
 	ERR_INVALID_FORMAT_REGEX        = "invalid format for %s, the text should match regex %s"
-	ERR_MAX_IMAGE_SIZE_EXCEED_LIMIT = "image size exceed limit %s MB. actual size %s. email %s"
+	ERR_MAX_IMAGE_SIZE_EXCEED_LIMIT = "image size exceed limit %d MB. actual size %d. email %s"
+	ERR_ONE_OF_FORMATTER            = "%s must be one of %s"
+	ERR_PASSWORD_WEAK               = "password is too easy to guess, try a longer phrase or fewer common words"
+	ERR_PASSWORD_PERSONAL           = "password must not contain your email or name"
+	ERR_PASSWORD_BREACHED           = "password has appeared in a data breach, please choose another one"
 )
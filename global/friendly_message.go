@@ -1,18 +1,59 @@
 package global
 
 const (
-	FRIENDLY_MESSAGE                 = "An error occured"
-	FRIENDLY_INVALID_USNME_PASSWORD  = "Invalid email or password"
-	FRIENDLY_DUPLICATE_EMAIL         = "Email has already been used"
-	FRIENDLY_INVALID_EMAIL           = "Invalid email"
-	FRIENDLY_INVALID_TOKEN           = "Token is invalid"
-	FRIENDLY_TOKEN_EXPIRED           = "Token is expired"
-	FRIENDLY_TOKEN_REQUIRED          = "Token is required"
-	FRIENDLY_IMAGE_REQUIRED          = "Image is required"
-	FRIENDLY_EMAIL_NOT_VERIFIED      = "Email has not been verified"
-	FRIENDLY_INVALID_EMAIL_FORMAT    = "Invalid email format"
-	FRIENDLY_IMAGE_NOT_ALLOWED       = "Image type %s is not allowed"
-	FRIENDLY_INVALID_FORMAT          = "invalid format for %s"
-	FRIENDLY_INVALID_PARAM           = "Invalid param"
-	FRIENDLY_IMAGE_SIZE_EXCEED_LIMIT = "Max image size is %d MB"
+	FRIENDLY_MESSAGE                   = "An error occured"
+	FRIENDLY_INVALID_USNME_PASSWORD    = "Invalid email or password"
+	FRIENDLY_DUPLICATE_EMAIL           = "Email has already been used"
+	FRIENDLY_INVALID_EMAIL             = "Invalid email"
+	FRIENDLY_INVALID_TOKEN             = "Token is invalid"
+	FRIENDLY_TOKEN_EXPIRED             = "Token is expired"
+	FRIENDLY_TOKEN_REQUIRED            = "Token is required"
+	FRIENDLY_IMAGE_REQUIRED            = "Image is required"
+	FRIENDLY_EMAIL_NOT_VERIFIED        = "Email has not been verified"
+	FRIENDLY_INVALID_EMAIL_FORMAT      = "Invalid email format"
+	FRIENDLY_IMAGE_NOT_ALLOWED         = "Image type %s is not allowed"
+	FRIENDLY_INVALID_FORMAT            = "invalid format for %s"
+	FRIENDLY_INVALID_PARAM             = "Invalid param"
+	FRIENDLY_INVALID_BBOX              = "Invalid bbox, the format is min_lon,min_lat,max_lon,max_lat"
+	FRIENDLY_IMAGE_SIZE_EXCEED_LIMIT   = "Max image size is %d MB"
+	FRIENDLY_COLLECTION_NOT_FOUND      = "Collection is not found"
+	FRIENDLY_POST_NOT_FOUND            = "Post is not found"
+	FRIENDLY_MAX_PINNED_POST           = "You can only pin up to %d posts"
+	FRIENDLY_SHARE_LINK_NOT_FOUND      = "Share link is not found"
+	FRIENDLY_SHARE_LINK_EXPIRED        = "Share link is expired"
+	FRIENDLY_SHARE_LINK_PASSWORD       = "Share link password is incorrect"
+	FRIENDLY_SHARE_LINK_INVALID_EXPIRY = "Share link expiry must be in the future"
+	FRIENDLY_STREAM_TICKET_INVALID     = "Stream ticket is invalid or expired"
+	FRIENDLY_SHARE_LINK_LOCKED         = "Too many incorrect share link passwords, please try again later"
+	FRIENDLY_DUPLICATE_USERNAME        = "Username has already been used"
+	FRIENDLY_INVALID_USERNAME          = "Username must be 3-30 characters of lowercase letters, numbers or underscore"
+	FRIENDLY_PROFILE_NOT_FOUND         = "Profile is not found"
+	FRIENDLY_ALREADY_FOLLOWED          = "You have already followed or requested to follow this account"
+	FRIENDLY_FOLLOW_NOT_FOUND          = "Follow relation is not found"
+	FRIENDLY_FOLLOW_SELF               = "You cannot follow yourself"
+	FRIENDLY_COMMENT_NOT_FOUND         = "Comment is not found"
+	FRIENDLY_INVALID_PARENT_COMMENT    = "Parent comment does not belong to this post"
+	FRIENDLY_JOURNAL_NOT_FOUND         = "Journal is not found"
+	FRIENDLY_INVITATION_NOT_FOUND      = "Invitation is not found or has expired"
+	FRIENDLY_JOURNAL_FORBIDDEN         = "You do not have permission to do this in the journal"
+	FRIENDLY_INVITATION_EMAIL          = "Invitation was sent to another email"
+	FRIENDLY_JOURNAL_OWNER             = "The owner of the journal cannot be changed or removed"
+	FRIENDLY_RESTRICT_SELF             = "You cannot block or mute yourself"
+	FRIENDLY_BLOCKED                   = "You cannot interact with this account"
+	FRIENDLY_INVALID_PASSWORD          = "Password is incorrect"
+	FRIENDLY_TWO_FACTOR_ENABLED        = "Two-factor authentication is already enabled"
+	FRIENDLY_TWO_FACTOR_NOT_SETUP      = "Two-factor authentication has not been set up"
+	FRIENDLY_INVALID_TWO_FACTOR_CODE   = "Invalid authentication code"
+	FRIENDLY_TWO_FACTOR_CHALLENGE      = "Login session has expired, please log in again"
+	FRIENDLY_PASSKEY_INVALID           = "Passkey could not be verified"
+	FRIENDLY_PASSKEY_NOT_FOUND         = "Passkey is not found"
+	FRIENDLY_OIDC_PROVIDER_NOT_FOUND   = "Sign in provider is not found"
+	FRIENDLY_OIDC_FAILED               = "Sign in with the provider failed, please try again"
+	FRIENDLY_OIDC_EMAIL_NOT_VERIFIED   = "The provider did not confirm your email address"
+	FRIENDLY_MAGIC_LINK_INVALID        = "Sign in link is invalid or has expired"
+	FRIENDLY_PASSWORD_POLICY           = "Password does not meet the password policy"
+	FRIENDLY_LOGIN_LOCKED              = "Too many failed login attempts, please try again later"
+	FRIENDLY_SESSION_NOT_FOUND         = "Session is not found"
+	FRIENDLY_TOKEN_REUSED              = "Your session was signed out for your security, please log in again"
+	FRIENDLY_OIDC_ACCOUNT_UNVERIFIED   = "An account with this email has not been verified, please verify it before signing in with the provider"
 )
@@ -2,3 +2,6 @@ package global
 
 const VERIFY_EMAIL_TEMPLATE = `Please click this <a href="%s">link</a> to verify email.`
 const RESET_PASSWORD_TEMPLATE = `Please click this <a href="%s">link</a> to change your password.`
+const JOURNAL_INVITATION_TEMPLATE = `You are invited to write in the journal %s. Please click this <a href="%s">link</a> to join.`
+const MAGIC_LINK_TEMPLATE = `Please click this <a href="%s">link</a> to sign in. The link can only be used once and expires in %d minutes.`
+const LOGIN_LOCKED_TEMPLATE = `Sign in to your account was blocked for %d minutes after %d failed attempts, the last one from %s. If this was not you, please change your password.`
@@ -7,9 +7,8 @@ require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/ghodss/yaml v1.0.0 // indirect
 	github.com/gin-contrib/cors v1.3.1
-	github.com/gin-gonic/gin v1.6.3
-	github.com/go-playground/validator v9.31.0+incompatible
-	github.com/go-playground/validator/v10 v10.4.1
+	github.com/gin-gonic/gin v1.8.1
+	github.com/go-playground/validator/v10 v10.10.0
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gomodule/redigo v1.8.3
 	github.com/google/uuid v1.1.2
@@ -18,5 +17,6 @@ require (
 	github.com/microcosm-cc/bluemonday v1.0.4
 	github.com/stretchr/stew v0.0.0-20130812190256-80ef0842b48b
 	github.com/tkanos/gonfig v0.0.0-20181112185242-896f3d81fadf
-	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	github.com/yuin/goldmark v1.4.13
+	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
 )
@@ -1,20 +1,35 @@
 package helper
 
 import (
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
 	"github.com/pajri/personal-backend/global"
 )
 
+// token_use claim values, every token is signed with the same key so a
+// token of one use must not be accepted for another
+const (
+	TOKEN_USE_ACCESS             = "access"
+	TOKEN_USE_REFRESH            = "refresh"
+	TOKEN_USE_EMAIL_VERIFICATION = "email_verification"
+	TOKEN_USE_RESET_PASSWORD     = "reset_password"
+)
+
 type JWTWrapper struct {
 	AccessToken         string    `json:"access_token"`
 	RefreshToken        string    `json:"refresh_token"`
 	RefreshTokenExpTime time.Time `json:"-"`
 }
 
+// JWT_ALGORITHMS are the only algorithms ParseToken accepts, the key named
+// by the kid header pins which one of them a token must use
+var JWT_ALGORITHMS = []string{jwt.SigningMethodRS256.Alg(), SigningMethodEdDSA.Alg()}
+
+// JWTHelper signs and verifies with the key set loaded by InitJWTKeys
 type JWTHelper struct {
 }
 
@@ -25,12 +40,12 @@ func (j JWTHelper) CreateTokenPair(accessTokenParam, refreshTokenParam jwt.MapCl
 	)
 
 	token = new(JWTWrapper)
-	token.AccessToken, err = j.CreateToken(accessTokenParam)
+	token.AccessToken, err = j.CreateToken(TOKEN_USE_ACCESS, accessTokenParam)
 	if err != nil {
 		return nil, err
 	}
 
-	token.RefreshToken, err = j.CreateToken(refreshTokenParam)
+	token.RefreshToken, err = j.CreateToken(TOKEN_USE_REFRESH, refreshTokenParam)
 	if err != nil {
 		return nil, err
 	}
@@ -38,9 +53,16 @@ func (j JWTHelper) CreateTokenPair(accessTokenParam, refreshTokenParam jwt.MapCl
 	return token, nil
 }
 
-func (j JWTHelper) CreateToken(claims jwt.MapClaims) (string, error) {
-	jwtWithClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := jwtWithClaims.SignedString([]byte(os.Getenv("JWT_SECRET")))
+// CreateToken adds the iss, aud and token_use claims ParseToken checks
+func (j JWTHelper) CreateToken(tokenUse string, claims jwt.MapClaims) (string, error) {
+	claims["iss"] = j.issuer()
+	claims["aud"] = j.audience(tokenUse)
+	claims["token_use"] = tokenUse
+
+	key := jwtKeys.signing
+	jwtWithClaims := jwt.NewWithClaims(key.method, claims)
+	jwtWithClaims.Header["kid"] = key.id
+	token, err := jwtWithClaims.SignedString(key.private)
 	if err != nil {
 		return "", cerror.NewAndPrintWithTag("CTH00", err, global.FRIENDLY_MESSAGE)
 	}
@@ -48,13 +70,12 @@ func (j JWTHelper) CreateToken(claims jwt.MapClaims) (string, error) {
 	return token, nil
 }
 
-func (j JWTHelper) ParseToken(tokenString string) (*jwt.Token, error) {
+// ParseToken only accepts tokens this backend issued for tokenUse
+func (j JWTHelper) ParseToken(tokenString, tokenUse string) (*jwt.Token, error) {
 	claims := jwt.MapClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims,
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+	parser := jwt.Parser{ValidMethods: JWT_ALGORITHMS}
+	token, err := parser.ParseWithClaims(tokenString, claims, jwtKeys.verificationKey)
 
 	if err != nil {
 		friendlyMessage := global.FRIENDLY_INVALID_TOKEN
@@ -71,5 +92,34 @@ func (j JWTHelper) ParseToken(tokenString string) (*jwt.Token, error) {
 		return token, cerr
 	}
 
+	use, _ := claims["token_use"].(string)
+	if !claims.VerifyIssuer(j.issuer(), true) || !claims.VerifyAudience(j.audience(tokenUse), true) || use != tokenUse {
+		err = fmt.Errorf("token for %s of %v is used as %s token", claims["aud"], claims["iss"], tokenUse)
+		return nil, cerror.NewAndPrintWithTag("PJW01", err, global.FRIENDLY_INVALID_TOKEN)
+	}
+
 	return token, nil
 }
+
+// issuer defaults to the backend host
+func (j JWTHelper) issuer() string {
+	if config.Config.JWT.Issuer != "" {
+		return config.Config.JWT.Issuer
+	}
+	return config.Config.Host
+}
+
+// audience is Audience only for access tokens, which other services may
+// verify through the JWKS. The other tokens are only meant for this
+// backend, so a service checking aud never accepts them
+func (j JWTHelper) audience(tokenUse string) string {
+	if tokenUse == TOKEN_USE_ACCESS && config.Config.JWT.Audience != "" {
+		return config.Config.JWT.Audience
+	}
+	return j.issuer()
+}
+
+// JWKS returns the public keys other services verify our tokens with
+func (j JWTHelper) JWKS() JWKS {
+	return jwtKeys.jwks()
+}
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pajri/personal-backend/config"
+)
+
+func setTestJWTKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := &jwtKey{id: "test", method: SigningMethodEdDSA, private: private, public: public}
+	jwtKeys = &jwtKeySet{signing: key, keys: map[string]*jwtKey{key.id: key}}
+
+	config.Config.Host = "https://api.example.com"
+	config.Config.JWT = config.JWTConfig{Audience: "example-api"}
+}
+
+func TestParseTokenUse(t *testing.T) {
+	setTestJWTKeys(t)
+	jwtHelper := JWTHelper{}
+
+	uses := []string{TOKEN_USE_ACCESS, TOKEN_USE_REFRESH, TOKEN_USE_EMAIL_VERIFICATION, TOKEN_USE_RESET_PASSWORD}
+	for _, created := range uses {
+		token, err := jwtHelper.CreateToken(created, jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, parsed := range uses {
+			_, err = jwtHelper.ParseToken(token, parsed)
+			if accepted := err == nil; accepted != (created == parsed) {
+				t.Errorf("%s token parsed as %s: accepted = %v", created, parsed, accepted)
+			}
+		}
+	}
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	setTestJWTKeys(t)
+	jwtHelper := JWTHelper{}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		valid  bool
+	}{
+		{"issued by us", jwt.MapClaims{"iss": "https://api.example.com", "aud": "example-api", "token_use": TOKEN_USE_ACCESS}, true},
+		{"other issuer", jwt.MapClaims{"iss": "https://other.example.com", "aud": "example-api", "token_use": TOKEN_USE_ACCESS}, false},
+		{"other audience", jwt.MapClaims{"iss": "https://api.example.com", "aud": "other-api", "token_use": TOKEN_USE_ACCESS}, false},
+		{"no issuer", jwt.MapClaims{"aud": "example-api", "token_use": TOKEN_USE_ACCESS}, false},
+		{"no audience", jwt.MapClaims{"iss": "https://api.example.com", "token_use": TOKEN_USE_ACCESS}, false},
+		{"no token use", jwt.MapClaims{"iss": "https://api.example.com", "aud": "example-api"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//signed directly so CreateToken does not fill in the claims
+			jwtWithClaims := jwt.NewWithClaims(jwtKeys.signing.method, tt.claims)
+			jwtWithClaims.Header["kid"] = jwtKeys.signing.id
+			token, err := jwtWithClaims.SignedString(jwtKeys.signing.private)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = jwtHelper.ParseToken(token, TOKEN_USE_ACCESS)
+			if valid := err == nil; valid != tt.valid {
+				t.Errorf("ParseToken error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/global"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+type IMarkdown interface {
+	Render(source string) (string, error)
+}
+
+type Markdown struct {
+	Renderer goldmark.Markdown
+	Policy   *bluemonday.Policy
+}
+
+func NewMarkdownHelper() IMarkdown {
+	//the renderer must not emit raw html from the source,
+	//the sanitizer below is applied to the rendered output only
+	renderer := goldmark.New(
+		goldmark.WithExtensions(
+			extension.Linkify,
+			extension.Strikethrough,
+			extension.TaskList,
+		),
+	)
+
+	//ugc policy plus the checkbox rendered by task list
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return Markdown{
+		Renderer: renderer,
+		Policy:   policy,
+	}
+}
+
+func (m Markdown) Render(source string) (string, error) {
+	var buf bytes.Buffer
+	err := m.Renderer.Convert([]byte(source), &buf)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("RMD00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return m.Policy.Sanitize(buf.String()), nil
+}
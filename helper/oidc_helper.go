@@ -0,0 +1,452 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/global"
+)
+
+const (
+	OIDC_HTTP_TIMEOUT = 10 * time.Second
+
+	//OIDC_CLOCK_SKEW tolerates clock differences with the provider
+	OIDC_CLOCK_SKEW = time.Minute
+
+	//OIDC_JWKS_REFRESH limits refetching the key set for unknown key ids
+	OIDC_JWKS_REFRESH = time.Minute
+
+	oidcMaxResponseSize = 1 << 20
+)
+
+var oidcDefaultScopes = []string{"openid", "email", "profile"}
+
+type IOIDC interface {
+	//AuthorizationURL returns the url the browser is sent to, codeVerifier is
+	//the PKCE secret the challenge is derived from
+	AuthorizationURL(provider, state, nonce, codeVerifier string) (string, error)
+
+	//Exchange redeems the authorization code and returns the identity of
+	//the validated id token
+	Exchange(provider, code, codeVerifier, nonce string) (*OIDCIdentity, error)
+}
+
+type OIDCIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type OIDC struct {
+	providers map[string]*oidcProvider
+	client    *http.Client
+}
+
+// oidcProvider caches the discovery document and the signing keys
+type oidcProvider struct {
+	config config.OIDCProviderConfig
+
+	mutex         sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcJWKS struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	} `json:"keys"`
+}
+
+func NewOIDCHelper() IOIDC {
+	providers := make(map[string]*oidcProvider)
+	for _, providerConfig := range config.Config.OIDCProviders {
+		providers[providerConfig.Name] = &oidcProvider{config: providerConfig}
+	}
+
+	return &OIDC{
+		providers: providers,
+		client:    &http.Client{Timeout: OIDC_HTTP_TIMEOUT},
+	}
+}
+
+func (o *OIDC) AuthorizationURL(providerName, state, nonce, codeVerifier string) (string, error) {
+	provider, err := o.getProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	discovery, err := o.getDiscovery(provider)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("OAU00", err, global.FRIENDLY_OIDC_FAILED)
+	}
+
+	scopes := provider.config.Scopes
+	if len(scopes) == 0 {
+		scopes = oidcDefaultScopes
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.config.ClientID)
+	query.Set("redirect_uri", provider.config.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+func (o *OIDC) Exchange(providerName, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	provider, err := o.getProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	discovery, err := o.getDiscovery(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	/*start token request*/
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.config.RedirectURL)
+	form.Set("client_id", provider.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("OEX00", err, global.FRIENDLY_OIDC_FAILED)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if provider.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(provider.config.ClientID), url.QueryEscape(provider.config.ClientSecret))
+	}
+
+	var tokenResponse oidcTokenResponse
+	status, err := o.doJSON(request, &tokenResponse)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("OEX01", err, global.FRIENDLY_OIDC_FAILED)
+	}
+
+	if status != http.StatusOK || tokenResponse.IDToken == "" {
+		errMsg := fmt.Sprintf("token endpoint of %s returned %d %s %s", providerName, status, tokenResponse.Error, tokenResponse.ErrorDescription)
+		return nil, cerror.NewAndPrintWithTag("OEX02", errors.New(errMsg), global.FRIENDLY_OIDC_FAILED)
+	}
+	/*end token request*/
+
+	return o.verifyIDToken(provider, discovery, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken validates the signature with the provider keys and the
+// claims required by OpenID Connect Core section 3.1.3.7
+func (o *OIDC) verifyIDToken(provider *oidcProvider, discovery *oidcDiscovery, idToken, nonce string) (*OIDCIdentity, error) {
+	//time based claims are checked below with clock skew
+	parser := jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()},
+		SkipClaimsValidation: true,
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.getKey(provider, discovery, kid)
+	})
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("OVT00", err, global.FRIENDLY_OIDC_FAILED)
+	}
+
+	var (
+		now      = time.Now()
+		issuer   = claims["iss"]
+		subject  = o.stringClaim(claims, "sub")
+		audience = o.audienceClaim(claims)
+		azp      = o.stringClaim(claims, "azp")
+		exp, _   = claims["exp"].(float64)
+		iat, _   = claims["iat"].(float64)
+		errMsg   string
+	)
+
+	switch {
+	case issuer != discovery.Issuer:
+		errMsg = fmt.Sprintf("id token issuer %v does not match %s", issuer, discovery.Issuer)
+	case subject == "":
+		errMsg = "id token has no subject"
+	case !o.contains(audience, provider.config.ClientID):
+		errMsg = fmt.Sprintf("id token audience %v does not contain the client id", audience)
+	case len(audience) > 1 && azp != provider.config.ClientID:
+		errMsg = fmt.Sprintf("id token authorized party %s is not the client id", azp)
+	case now.Add(-OIDC_CLOCK_SKEW).Unix() >= int64(exp):
+		errMsg = "id token is expired"
+	case now.Add(OIDC_CLOCK_SKEW).Unix() < int64(iat):
+		errMsg = "id token is issued in the future"
+	case o.stringClaim(claims, "nonce") != nonce:
+		errMsg = "id token nonce does not match"
+	}
+
+	if errMsg != "" {
+		return nil, cerror.NewAndPrintWithTag("OVT01", errors.New(errMsg), global.FRIENDLY_OIDC_FAILED)
+	}
+
+	//some providers send email_verified as a string
+	emailVerified, ok := claims["email_verified"].(bool)
+	if !ok {
+		emailVerified = o.stringClaim(claims, "email_verified") == "true"
+	}
+
+	name := o.stringClaim(claims, "name")
+	if name == "" {
+		name = strings.TrimSpace(o.stringClaim(claims, "given_name") + " " + o.stringClaim(claims, "family_name"))
+	}
+
+	identity := OIDCIdentity{
+		Provider:      provider.config.Name,
+		Subject:       subject,
+		Email:         strings.ToLower(o.stringClaim(claims, "email")),
+		EmailVerified: emailVerified,
+		Name:          name,
+	}
+	return &identity, nil
+}
+
+func (o *OIDC) getProvider(providerName string) (*oidcProvider, error) {
+	provider, ok := o.providers[providerName]
+	if !ok {
+		cerr := cerror.NewAndPrintWithTag("OPV00", fmt.Errorf("oidc provider %s is not configured", providerName), global.FRIENDLY_OIDC_PROVIDER_NOT_FOUND)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	return provider, nil
+}
+
+func (o *OIDC) getDiscovery(provider *oidcProvider) (*oidcDiscovery, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if provider.discovery != nil {
+		return provider.discovery, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(provider.config.Issuer, "/") + "/.well-known/openid-configuration"
+	request, err := http.NewRequest(http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ODS00", err, global.FRIENDLY_OIDC_FAILED)
+	}
+
+	var discovery oidcDiscovery
+	status, err := o.doJSON(request, &discovery)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ODS01", err, global.FRIENDLY_OIDC_FAILED)
+	}
+
+	if status != http.StatusOK || discovery.Issuer != provider.config.Issuer ||
+		discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		errMsg := fmt.Sprintf("invalid discovery document of %s with status %d and issuer %s", provider.config.Name, status, discovery.Issuer)
+		return nil, cerror.NewAndPrintWithTag("ODS02", errors.New(errMsg), global.FRIENDLY_OIDC_FAILED)
+	}
+
+	provider.discovery = &discovery
+	return provider.discovery, nil
+}
+
+// getKey returns the signing key of kid, the key set is refetched when the
+// provider has rotated its keys
+func (o *OIDC) getKey(provider *oidcProvider, discovery *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	key, ok := o.findKey(provider.keys, kid)
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(provider.keysFetchedAt) < OIDC_JWKS_REFRESH {
+		return nil, fmt.Errorf("signing key %s is not found", kid)
+	}
+
+	keys, err := o.fetchKeys(discovery.JWKSURI)
+	provider.keysFetchedAt = time.Now()
+	if err != nil {
+		return nil, err
+	}
+	provider.keys = keys
+
+	key, ok = o.findKey(provider.keys, kid)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not found", kid)
+	}
+
+	return key, nil
+}
+
+// findKey accepts a token without kid when the provider has a single key
+func (o *OIDC) findKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, ok := keys[kid]
+	return key, ok
+}
+
+func (o *OIDC) fetchKeys(jwksURI string) (map[string]crypto.PublicKey, error) {
+	request, err := http.NewRequest(http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks oidcJWKS
+	status, err := o.doJSON(request, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned %d", status)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+
+		case "EC":
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if jwk.Crv != "P-256" || errX != nil || errY != nil {
+				continue
+			}
+			key := &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !key.Curve.IsOnCurve(key.X, key.Y) {
+				continue
+			}
+			keys[jwk.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (o *OIDC) doJSON(request *http.Request, target interface{}) (int, error) {
+	response, err := o.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, oidcMaxResponseSize))
+	if err != nil {
+		return 0, err
+	}
+
+	//error responses of the token endpoint are json as well
+	err = json.Unmarshal(body, target)
+	if err != nil && response.StatusCode == http.StatusOK {
+		return 0, err
+	}
+
+	return response.StatusCode, nil
+}
+
+func (o *OIDC) stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// audienceClaim reads aud, which is either a string or an array of strings
+func (o *OIDC) audienceClaim(claims jwt.MapClaims) []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		var audience []string
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		return audience
+	}
+	return nil
+}
+
+func (o *OIDC) contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package helper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pajri/personal-backend/config"
+)
+
+const (
+	mockClientID = "mymoment"
+	mockNonce    = "nonce-1"
+	mockVerifier = "verifier-1"
+)
+
+// mockProvider serves discovery, jwks and a token endpoint that returns
+// idToken for the authorization code "code-1"
+type mockProvider struct {
+	server *httptest.Server
+
+	mutex        sync.Mutex
+	keys         map[string]interface{}
+	idToken      string
+	jwksRequests int
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	mock := &mockProvider{keys: make(map[string]interface{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                mock.server.URL,
+			AuthorizationEndpoint: mock.server.URL + "/authorize",
+			TokenEndpoint:         mock.server.URL + "/token",
+			JWKSURI:               mock.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		mock.mutex.Lock()
+		defer mock.mutex.Unlock()
+
+		mock.jwksRequests++
+		json.NewEncoder(w).Encode(mock.jwks())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code-1" || r.PostFormValue("code_verifier") != mockVerifier {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(oidcTokenResponse{Error: "invalid_grant"})
+			return
+		}
+
+		mock.mutex.Lock()
+		defer mock.mutex.Unlock()
+		json.NewEncoder(w).Encode(oidcTokenResponse{IDToken: mock.idToken})
+	})
+
+	mock.server = httptest.NewServer(mux)
+	t.Cleanup(mock.server.Close)
+	return mock
+}
+
+func (m *mockProvider) jwks() map[string][]map[string]string {
+	var keys []map[string]string
+	for kid, key := range m.keys {
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case *ecdsa.PrivateKey:
+			keys = append(keys, map[string]string{
+				"kty": "EC", "kid": kid, "use": "sig", "crv": "P-256",
+				"x": base64.RawURLEncoding.EncodeToString(k.X.Bytes()),
+				"y": base64.RawURLEncoding.EncodeToString(k.Y.Bytes()),
+			})
+		}
+	}
+	return map[string][]map[string]string{"keys": keys}
+}
+
+func (m *mockProvider) addKey(kid string, key interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.keys[kid] = key
+}
+
+func (m *mockProvider) setIDToken(idToken string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.idToken = idToken
+}
+
+func (m *mockProvider) jwksRequestCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.jwksRequests
+}
+
+func (m *mockProvider) helper() *OIDC {
+	providerConfig := config.OIDCProviderConfig{
+		Name:        "mock",
+		Issuer:      m.server.URL,
+		ClientID:    mockClientID,
+		RedirectURL: "https://mymoment.example.com/oidc/callback",
+	}
+
+	return &OIDC{
+		providers: map[string]*oidcProvider{"mock": {config: providerConfig}},
+		client:    m.server.Client(),
+	}
+}
+
+// validClaims is what the mock provider issues, cases change one claim
+func (m *mockProvider) validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "subject-1",
+		"aud":            mockClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          mockNonce,
+		"email":          "Budi@Example.com",
+		"email_verified": true,
+		"name":           "Budi Santoso",
+	}
+}
+
+func signIDToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	idToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idToken
+}
+
+func TestOIDCExchange(t *testing.T) {
+	mock := newMockProvider(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.addKey("rsa-1", rsaKey)
+	mock.addKey("ec-1", ecKey)
+
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := mock.validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name          string
+		idToken       string
+		valid         bool
+		emailVerified bool
+	}{
+		{"rs256", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, mock.validClaims()), true, true},
+		{"es256", signIDToken(t, jwt.SigningMethodES256, "ec-1", ecKey, mock.validClaims()), true, true},
+		{"wrong issuer", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("iss", "https://evil.example.com")), false, false},
+		{"wrong audience", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("aud", "other-client")), false, false},
+		{"audiences without azp", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("aud", []string{mockClientID, "other-client"})), false, false},
+		{"audiences with other azp", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey,
+			func() jwt.MapClaims {
+				claims := with("aud", []string{mockClientID, "other-client"})
+				claims["azp"] = "other-client"
+				return claims
+			}()), false, false},
+		{"audiences with azp", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey,
+			func() jwt.MapClaims {
+				claims := with("aud", []string{mockClientID, "other-client"})
+				claims["azp"] = mockClientID
+				return claims
+			}()), true, true},
+		{"no subject", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("sub", nil)), false, false},
+		{"nonce mismatch", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("nonce", "nonce-2")), false, false},
+		{"no nonce", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("nonce", nil)), false, false},
+		{"expired", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("exp", time.Now().Add(-2*OIDC_CLOCK_SKEW).Unix())), false, false},
+		{"expired within clock skew", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("exp", time.Now().Add(-OIDC_CLOCK_SKEW/2).Unix())), true, true},
+		{"no expiry", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("exp", nil)), false, false},
+		{"issued in the future", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("iat", time.Now().Add(2*OIDC_CLOCK_SKEW).Unix())), false, false},
+		{"hs256 with the client id", signIDToken(t, jwt.SigningMethodHS256, "rsa-1", []byte(mockClientID), mock.validClaims()), false, false},
+		{"es256 header on rsa key", signIDToken(t, jwt.SigningMethodES256, "rsa-1", ecKey, mock.validClaims()), false, false},
+		{"rs256 header on ec key", signIDToken(t, jwt.SigningMethodRS256, "ec-1", rsaKey, mock.validClaims()), false, false},
+		{"signed by another key", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", func() *rsa.PrivateKey {
+			key, _ := rsa.GenerateKey(rand.Reader, 2048)
+			return key
+		}(), mock.validClaims()), false, false},
+		{"no kid with several keys", signIDToken(t, jwt.SigningMethodRS256, "", rsaKey, mock.validClaims()), false, false},
+		{"email not verified", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("email_verified", false)), true, false},
+		{"email verified as string", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("email_verified", "true")), true, true},
+		{"email not verified as string", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("email_verified", "false")), true, false},
+		{"email verified missing", signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, with("email_verified", nil)), true, false},
+	}
+
+	oidc := mock.helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.setIDToken(tt.idToken)
+
+			identity, err := oidc.Exchange("mock", "code-1", mockVerifier, mockNonce)
+			if valid := err == nil; valid != tt.valid {
+				t.Fatalf("Exchange error = %v, want valid %v", err, tt.valid)
+			}
+			if err != nil {
+				return
+			}
+
+			if identity.Subject != "subject-1" || identity.Email != "budi@example.com" || identity.Name != "Budi Santoso" {
+				t.Errorf("identity = %+v", identity)
+			}
+			if identity.EmailVerified != tt.emailVerified {
+				t.Errorf("EmailVerified = %v, want %v", identity.EmailVerified, tt.emailVerified)
+			}
+		})
+	}
+
+	if requests := mock.jwksRequestCount(); requests != 1 {
+		t.Errorf("jwks requested %d times, want 1", requests)
+	}
+}
+
+func TestOIDCExchangeRejectedCode(t *testing.T) {
+	mock := newMockProvider(t)
+
+	_, err := mock.helper().Exchange("mock", "code-1", "other-verifier", mockNonce)
+	if err == nil {
+		t.Error("Exchange with a wrong code verifier succeeded")
+	}
+}
+
+func TestOIDCUnknownKidRefetch(t *testing.T) {
+	mock := newMockProvider(t)
+	oidc := mock.helper()
+
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.addKey("old", oldKey)
+
+	exchange := func(kid string, key *ecdsa.PrivateKey) error {
+		mock.setIDToken(signIDToken(t, jwt.SigningMethodES256, kid, key, mock.validClaims()))
+		_, err := oidc.Exchange("mock", "code-1", mockVerifier, mockNonce)
+		return err
+	}
+
+	if err = exchange("old", oldKey); err != nil {
+		t.Fatalf("Exchange with the first key: %v", err)
+	}
+
+	//the provider rotates its key right after the first fetch
+	mock.addKey("new", newKey)
+	for i := 0; i < 3; i++ {
+		if err = exchange("new", newKey); err == nil {
+			t.Fatal("Exchange with a key added within OIDC_JWKS_REFRESH succeeded without a refetch")
+		}
+	}
+	if requests := mock.jwksRequestCount(); requests != 1 {
+		t.Fatalf("unknown kids within OIDC_JWKS_REFRESH requested the jwks %d times, want 1", requests)
+	}
+
+	oidc.providers["mock"].keysFetchedAt = time.Now().Add(-OIDC_JWKS_REFRESH)
+	if err = exchange("new", newKey); err != nil {
+		t.Fatalf("Exchange with the rotated key: %v", err)
+	}
+	if err = exchange("new", newKey); err != nil {
+		t.Fatalf("Exchange with the cached rotated key: %v", err)
+	}
+	if requests := mock.jwksRequestCount(); requests != 2 {
+		t.Errorf("jwks requested %d times, want 2", requests)
+	}
+
+	//a kid the provider does not have is refetched once, then not again
+	oidc.providers["mock"].keysFetchedAt = time.Now().Add(-OIDC_JWKS_REFRESH)
+	for i := 0; i < 3; i++ {
+		if err = exchange("missing", newKey); err == nil {
+			t.Fatal("Exchange with an unknown kid succeeded")
+		}
+	}
+	if requests := mock.jwksRequestCount(); requests != 3 {
+		t.Errorf("jwks requested %d times, want 3", requests)
+	}
+}
@@ -0,0 +1,173 @@
+package helper
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	PASSWORD_MIN_LENGTH   = 10
+	PASSWORD_MAX_LENGTH   = 128
+	PASSWORD_MIN_STRENGTH = util.PASSWORD_SCORE_SAFELY_UNGUESSABLE
+
+	hibpPrefixLength = 5
+)
+
+type IPasswordPolicy interface {
+	//Validate returns a PasswordPolicyError listing every rule password
+	//breaks, userInputs are the email and names it must not contain
+	Validate(password string, userInputs ...string) error
+}
+
+// PasswordPolicyError Violations are shown to the user as validation messages
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e PasswordPolicyError) Error() string {
+	return strings.Join(e.Violations, "; ")
+}
+
+// PasswordPolicy BreachedPasswordPath is a directory of Pwned Passwords
+// range files named by the first five characters of the SHA-1 hash, the
+// same k-anonymity format the range api returns
+type PasswordPolicy struct {
+	minLength    int
+	minStrength  int
+	breachedPath string
+}
+
+func NewPasswordPolicyHelper() IPasswordPolicy {
+	policy := &PasswordPolicy{
+		minLength:    config.Config.PasswordPolicy.MinLength,
+		minStrength:  config.Config.PasswordPolicy.MinStrength,
+		breachedPath: config.Config.PasswordPolicy.BreachedPasswordPath,
+	}
+
+	if policy.minLength <= 0 {
+		policy.minLength = PASSWORD_MIN_LENGTH
+	}
+	if policy.minStrength <= 0 {
+		policy.minStrength = PASSWORD_MIN_STRENGTH
+	}
+
+	return policy
+}
+
+func (pp *PasswordPolicy) Validate(password string, userInputs ...string) error {
+	var violations []string
+
+	length := len([]rune(password))
+	if length < pp.minLength {
+		violations = append(violations, fmt.Sprintf(global.ERR_MIN_CHAR, "password", strconv.Itoa(pp.minLength)))
+	}
+
+	//the remaining checks are skipped for input no password could be
+	if length > PASSWORD_MAX_LENGTH {
+		violations = append(violations, fmt.Sprintf(global.ERR_MAX_CHAR, "password", strconv.Itoa(PASSWORD_MAX_LENGTH)))
+		return pp.policyError(violations)
+	}
+
+	if pp.containsUserInput(password, userInputs) {
+		violations = append(violations, global.ERR_PASSWORD_PERSONAL)
+	}
+
+	if util.PasswordStrength(password, userInputs...) < pp.minStrength {
+		violations = append(violations, global.ERR_PASSWORD_WEAK)
+	}
+
+	if pp.isBreached(password) {
+		violations = append(violations, global.ERR_PASSWORD_BREACHED)
+	}
+
+	if len(violations) > 0 {
+		return pp.policyError(violations)
+	}
+
+	return nil
+}
+
+func (pp *PasswordPolicy) policyError(violations []string) error {
+	err := PasswordPolicyError{Violations: violations}
+	cerror.NewAndPrintWithTag("VPP00", err, global.FRIENDLY_PASSWORD_POLICY)
+	return err
+}
+
+// containsUserInput checks the whole email, its local part and every word
+// of the names
+func (pp *PasswordPolicy) containsUserInput(password string, userInputs []string) bool {
+	password = strings.ToLower(password)
+
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		candidates := []string{input}
+		if at := strings.LastIndex(input, "@"); at > 0 {
+			candidates = append(candidates, input[:at])
+		}
+		candidates = append(candidates, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+
+		for _, candidate := range candidates {
+			if len([]rune(candidate)) >= 3 && strings.Contains(password, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isBreached reads the range file of the hash prefix, a missing file is
+// logged and treated as not breached so signup keeps working
+func (pp *PasswordPolicy) isBreached(password string) bool {
+	if pp.breachedPath == "" {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hibpPrefixLength], hash[hibpPrefixLength:]
+
+	file, err := os.Open(filepath.Join(pp.breachedPath, prefix+".txt"))
+	if os.IsNotExist(err) {
+		file, err = os.Open(filepath.Join(pp.breachedPath, prefix))
+	}
+	if err != nil {
+		cerror.NewAndPrintWithTag("IBP00", err, global.FRIENDLY_MESSAGE)
+		return false
+	}
+	defer file.Close()
+
+	//each line is SUFFIX:COUNT
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			colon = len(line)
+		}
+
+		if strings.EqualFold(line[:colon], suffix) {
+			return line[colon:] != ":0"
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		cerror.NewAndPrintWithTag("IBP01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:hibpPrefixLength], hash[hibpPrefixLength:]
+}
+
+// writeRangeFile writes lines to the range file of password's prefix
+func writeRangeFile(t *testing.T, dir, password, name string, lines ...string) {
+	prefix, _ := sha1Hex(password)
+	if name == "" {
+		name = prefix + ".txt"
+	}
+
+	content := strings.Join(lines, "\r\n") + "\r\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsBreached(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		fileName string
+		lines    func(suffix string) []string
+		want     bool
+	}{
+		{"listed", "password", "", func(suffix string) []string {
+			return []string{"0018A45C4D1DEF81644B54AB7F969B88D65:1", suffix + ":3861493", "00D4F6E8FA6EECAD2A3AA415EEC418D38EC:2"}
+		}, true},
+		{"lowercase suffix", "password", "", func(suffix string) []string {
+			return []string{strings.ToLower(suffix) + ":3861493"}
+		}, true},
+		{"padding line", "password", "", func(suffix string) []string {
+			return []string{"0018A45C4D1DEF81644B54AB7F969B88D65:1", suffix + ":0"}
+		}, false},
+		{"suffix without count", "password", "", func(suffix string) []string {
+			return []string{suffix}
+		}, true},
+		{"not listed", "password", "", func(suffix string) []string {
+			return []string{"0018A45C4D1DEF81644B54AB7F969B88D65:1", "00D4F6E8FA6EECAD2A3AA415EEC418D38EC:2"}
+		}, false},
+		{"file without extension", "password", "5BAA6", func(suffix string) []string {
+			return []string{suffix + ":3861493"}
+		}, true},
+		{"missing range file", "password", "00000.txt", func(suffix string) []string {
+			return []string{suffix + ":3861493"}
+		}, false},
+		{"empty range file", "password", "", func(suffix string) []string {
+			return nil
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			_, suffix := sha1Hex(tt.password)
+			writeRangeFile(t, dir, tt.password, tt.fileName, tt.lines(suffix)...)
+
+			policy := &PasswordPolicy{breachedPath: dir}
+			if got := policy.isBreached(tt.password); got != tt.want {
+				t.Errorf("isBreached(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBreachedWithoutPath(t *testing.T) {
+	policy := &PasswordPolicy{}
+	if policy.isBreached("password") {
+		t.Error("isBreached without a breached password path = true, want false")
+	}
+}
+
+func TestValidateBreached(t *testing.T) {
+	const password = "xK2pQ9vLm4Rt"
+
+	dir := t.TempDir()
+	_, suffix := sha1Hex(password)
+	writeRangeFile(t, dir, password, "", strings.ToLower(suffix)+":12")
+
+	policy := &PasswordPolicy{minLength: PASSWORD_MIN_LENGTH, minStrength: PASSWORD_MIN_STRENGTH, breachedPath: dir}
+	err := policy.Validate(password)
+	perr, ok := err.(PasswordPolicyError)
+	if !ok {
+		t.Fatalf("Validate(%q) = %v, want PasswordPolicyError", password, err)
+	}
+	if len(perr.Violations) != 1 {
+		t.Errorf("Violations = %q, want only the breached password", perr.Violations)
+	}
+
+	policy.breachedPath = t.TempDir()
+	writeRangeFile(t, policy.breachedPath, password, "", suffix+":0")
+	if err = policy.Validate(password); err != nil {
+		t.Errorf("Validate(%q) with a padding line = %v, want nil", password, err)
+	}
+}
@@ -3,6 +3,7 @@ package helper
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/pajri/personal-backend/adapter/cerror"
@@ -10,14 +11,31 @@ import (
 	"github.com/pajri/personal-backend/global"
 )
 
+const (
+	REDIS_MAX_IDLE     = 10
+	REDIS_IDLE_TIMEOUT = 4 * time.Minute
+)
+
 type IRedis interface {
 	Set(key string, value interface{}, exp int64) error
+	SetIfAbsent(key string, value interface{}, exp int64) (bool, error)
 	Get(key string) (string, error)
 	Delete(key string) error
+	Pop(key string) (string, error)
+	Increment(key string, exp int64) (int64, error)
+	Publish(channel string, message interface{}) error
+	Subscribe(channel string) (IRedisSubscription, error)
+}
+
+type IRedisSubscription interface {
+	Receive() ([]byte, error)
+	Close() error
 }
 
+// Redis takes a connection from Pool for every command, a single redigo
+// connection does not pair concurrent commands with their replies
 type Redis struct {
-	Client redis.Conn
+	Pool *redis.Pool
 }
 
 var RedisHelper IRedis
@@ -28,14 +46,24 @@ func InitRedis() {
 
 func NewRedisHelper() IRedis {
 	//Connect
-	var err error
 	address := fmt.Sprintf("%s:%v", config.Config.Redis.Host, config.Config.Redis.Port)
-	client, err := redis.Dial("tcp", address)
+	pool := &redis.Pool{
+		MaxIdle:     REDIS_MAX_IDLE,
+		IdleTimeout: REDIS_IDLE_TIMEOUT,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", address)
+		},
+	}
+
+	//fail on start like before instead of on the first request
+	conn := pool.Get()
+	_, err := conn.Do("PING")
+	conn.Close()
 	if err != nil {
 		log.Fatal("error redis.Dial : ", err)
 	}
 
-	RedisHelper = Redis{Client: client}
+	RedisHelper = Redis{Pool: pool}
 
 	// response, err := c.Do("AUTH", config.Config.Redis.Password)
 	// if err != nil {
@@ -47,23 +75,53 @@ func NewRedisHelper() IRedis {
 	return RedisHelper
 }
 
+// setScript stores the value and its expiry in one step, like
+// incrementScript
+var setScript = redis.NewScript(1, `redis.call("SET", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) ~= 0 then
+	redis.call("EXPIREAT", KEYS[1], ARGV[2])
+end
+return 1`)
+
+// Set keeps the key until exp, a unix time, or forever when exp is zero
 func (rh Redis) Set(key string, value interface{}, exp int64) error {
-	_, err := rh.Client.Do("SET", key, value)
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	_, err := setScript.Do(conn, key, value, exp)
 	if err != nil {
 		return cerror.NewAndPrintWithTag("SRV00", err, global.FRIENDLY_MESSAGE)
 	}
+	return nil
+}
 
-	if exp != 0 {
-		_, err = rh.Client.Do("EXPIREAT", key, exp)
-		if err != nil {
-			return cerror.NewAndPrintWithTag("SRV01", err, global.FRIENDLY_MESSAGE)
-		}
+// setIfAbsentScript is setScript for a key that does not exist yet
+var setIfAbsentScript = redis.NewScript(1, `if redis.call("SETNX", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+if tonumber(ARGV[2]) ~= 0 then
+	redis.call("EXPIREAT", KEYS[1], ARGV[2])
+end
+return 1`)
+
+// SetIfAbsent is Set that leaves an existing key alone, only one of
+// concurrent callers gets true
+func (rh Redis) SetIfAbsent(key string, value interface{}, exp int64) (bool, error) {
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	stored, err := redis.Bool(setIfAbsentScript.Do(conn, key, value, exp))
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("SNV00", err, global.FRIENDLY_MESSAGE)
 	}
-	return nil
+	return stored, nil
 }
 
 func (rh Redis) Get(key string) (string, error) {
-	value, err := redis.String(rh.Client.Do("GET", key))
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	value, err := redis.String(conn.Do("GET", key))
 	if err != nil {
 		return "", cerror.NewAndPrintWithTag("GRV00", err, global.FRIENDLY_MESSAGE)
 	}
@@ -71,9 +129,101 @@ func (rh Redis) Get(key string) (string, error) {
 }
 
 func (rh Redis) Delete(key string) error {
-	_, err := rh.Client.Do("DEL", key)
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", key)
 	if err != nil {
 		return cerror.NewAndPrintWithTag("DRV00", err, global.FRIENDLY_MESSAGE)
 	}
 	return nil
 }
+
+// popScript reads and deletes a key atomically, GETDEL needs redis 6.2
+var popScript = redis.NewScript(1, `local value = redis.call("GET", KEYS[1])
+redis.call("DEL", KEYS[1])
+return value`)
+
+// Pop returns the value of key and deletes it, only one caller gets the
+// value when the key is popped concurrently
+func (rh Redis) Pop(key string) (string, error) {
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	value, err := redis.String(popScript.Do(conn, key))
+	if err != nil && err != redis.ErrNil {
+		return "", cerror.NewAndPrintWithTag("PPV00", err, global.FRIENDLY_MESSAGE)
+	}
+	return value, nil
+}
+
+// incrementScript sets the expiry in the same step as the increment, a
+// counter left without one would never reset. A counter that has no
+// expiry yet also gets one
+var incrementScript = redis.NewScript(1, `local count = redis.call("INCR", KEYS[1])
+if tonumber(ARGV[1]) ~= 0 and (count == 1 or redis.call("TTL", KEYS[1]) == -1) then
+	redis.call("EXPIREAT", KEYS[1], ARGV[1])
+end
+return count`)
+
+// Increment adds one to the counter of key, exp is only applied when the
+// counter is created
+func (rh Redis) Increment(key string, exp int64) (int64, error) {
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	count, err := redis.Int64(incrementScript.Do(conn, key, exp))
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("IRV00", err, global.FRIENDLY_MESSAGE)
+	}
+	return count, nil
+}
+
+func (rh Redis) Publish(channel string, message interface{}) error {
+	conn := rh.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PUBLISH", channel, message)
+	if err != nil {
+		return cerror.NewAndPrintWithTag("PRV00", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
+
+// Subscribe opens a dedicated connection outside the pool, a connection in
+// subscribed state can not be used for other commands
+func (rh Redis) Subscribe(channel string) (IRedisSubscription, error) {
+	client, err := rh.Pool.Dial()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("SBR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	conn := redis.PubSubConn{Conn: client}
+	err = conn.Subscribe(channel)
+	if err != nil {
+		client.Close()
+		return nil, cerror.NewAndPrintWithTag("SBR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &RedisSubscription{conn: conn}, nil
+}
+
+type RedisSubscription struct {
+	conn redis.PubSubConn
+}
+
+// Receive blocks until a message is published to the channel
+func (rs *RedisSubscription) Receive() ([]byte, error) {
+	for {
+		switch message := rs.conn.Receive().(type) {
+		case redis.Message:
+			return message.Data, nil
+		case error:
+			return nil, message
+		}
+	}
+}
+
+func (rs *RedisSubscription) Close() error {
+	return rs.conn.Close()
+}
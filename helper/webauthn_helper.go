@@ -0,0 +1,300 @@
+package helper
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	WEBAUTHN_CEREMONY_CREATE = "webauthn.create"
+	WEBAUTHN_CEREMONY_GET    = "webauthn.get"
+
+	//COSE algorithm identifiers
+	COSE_ALG_ES256 = -7
+	COSE_ALG_EDDSA = -8
+	COSE_ALG_RS256 = -257
+)
+
+// authenticator data flags
+const (
+	webAuthnFlagUserPresent  = 0x01
+	webAuthnFlagUserVerified = 0x04
+	webAuthnFlagAttested     = 0x40
+)
+
+var WebAuthnAlgorithms = []int{COSE_ALG_ES256, COSE_ALG_EDDSA, COSE_ALG_RS256}
+
+type IWebAuthn interface {
+	RPID() string
+	RPName() string
+
+	//VerifyClientData checks the type and origin of the client data and
+	//returns the base64url challenge the client signed
+	VerifyClientData(clientDataJSON []byte, ceremony string) (challenge string, err error)
+
+	//VerifyAttestation returns the credential created by the authenticator,
+	//attestation statements are not verified since none is requested
+	VerifyAttestation(attestationObject []byte) (*AttestedCredential, error)
+
+	//VerifyAssertion checks the signature with the stored public key and
+	//returns the sign count reported by the authenticator
+	VerifyAssertion(publicKey, authenticatorData, clientDataJSON, signature []byte) (signCount uint32, err error)
+}
+
+type AttestedCredential struct {
+	CredentialID []byte
+	PublicKey    []byte
+	SignCount    uint32
+}
+
+type WebAuthn struct {
+	rpID   string
+	rpName string
+	origin string
+}
+
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func NewWebAuthnHelper() IWebAuthn {
+	return WebAuthn{
+		rpID:   config.Config.WebAuthn.RPID,
+		rpName: config.Config.WebAuthn.RPName,
+		origin: config.Config.WebAuthn.Origin,
+	}
+}
+
+func (w WebAuthn) RPID() string {
+	return w.rpID
+}
+
+func (w WebAuthn) RPName() string {
+	return w.rpName
+}
+
+func (w WebAuthn) VerifyClientData(clientDataJSON []byte, ceremony string) (string, error) {
+	var clientData webAuthnClientData
+	err := json.Unmarshal(clientDataJSON, &clientData)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("VCD00", err, global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	if clientData.Type != ceremony {
+		errMsg := fmt.Sprintf("client data type is %s instead of %s", clientData.Type, ceremony)
+		return "", cerror.NewAndPrintWithTag("VCD01", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	if clientData.Origin != w.origin {
+		errMsg := fmt.Sprintf("client data origin %s is not allowed", clientData.Origin)
+		return "", cerror.NewAndPrintWithTag("VCD02", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	return clientData.Challenge, nil
+}
+
+func (w WebAuthn) VerifyAttestation(attestationObject []byte) (*AttestedCredential, error) {
+	decoded, _, err := util.DecodeCBOR(attestationObject)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("VAT00", err, global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, cerror.NewAndPrintWithTag("VAT01", errors.New("attestation object is not a map"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	authData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, cerror.NewAndPrintWithTag("VAT02", errors.New("attestation object has no authData"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	signCount, err := w.verifyAuthenticatorData(authData, webAuthnFlagAttested)
+	if err != nil {
+		return nil, err
+	}
+
+	/*start parse attested credential data*/
+	//aaguid (16) | credential id length (2) | credential id | cose key
+	rest := authData[37:]
+	if len(rest) < 18 {
+		return nil, cerror.NewAndPrintWithTag("VAT03", errors.New("attested credential data is too short"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, cerror.NewAndPrintWithTag("VAT04", errors.New("invalid credential id length"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	credentialID := rest[:idLength]
+	_, keyLength, err := util.DecodeCBOR(rest[idLength:])
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("VAT05", err, global.FRIENDLY_PASSKEY_INVALID)
+	}
+	publicKey := rest[idLength : idLength+keyLength]
+	/*end parse attested credential data*/
+
+	//reject keys that could never verify an assertion
+	_, _, err = w.parsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := AttestedCredential{
+		CredentialID: append([]byte(nil), credentialID...),
+		PublicKey:    append([]byte(nil), publicKey...),
+		SignCount:    signCount,
+	}
+	return &credential, nil
+}
+
+func (w WebAuthn) VerifyAssertion(publicKey, authenticatorData, clientDataJSON, signature []byte) (uint32, error) {
+	signCount, err := w.verifyAuthenticatorData(authenticatorData, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	key, alg, err := w.parsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	var valid bool
+	switch alg {
+	case COSE_ALG_ES256:
+		valid = ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest[:], signature)
+	case COSE_ALG_RS256:
+		valid = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case COSE_ALG_EDDSA:
+		valid = ed25519.Verify(key.(ed25519.PublicKey), signed, signature)
+	}
+
+	if !valid {
+		return 0, cerror.NewAndPrintWithTag("VAS00", errors.New("assertion signature is invalid"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	return signCount, nil
+}
+
+// verifyAuthenticatorData checks the rp id hash and requires user presence
+// and user verification, passkeys replace both the password and a second factor
+func (w WebAuthn) verifyAuthenticatorData(authData []byte, requiredFlags byte) (uint32, error) {
+	//rp id hash (32) | flags (1) | sign count (4)
+	if len(authData) < 37 {
+		return 0, cerror.NewAndPrintWithTag("VAD00", errors.New("authenticator data is too short"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	rpIDHash := sha256.Sum256([]byte(w.rpID))
+	if !bytes.Equal(authData[:32], rpIDHash[:]) {
+		return 0, cerror.NewAndPrintWithTag("VAD01", errors.New("rp id hash does not match"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	flags := authData[32]
+	required := requiredFlags | webAuthnFlagUserPresent | webAuthnFlagUserVerified
+	if flags&required != required {
+		errMsg := fmt.Sprintf("authenticator flags %08b miss required flags %08b", flags, required)
+		return 0, cerror.NewAndPrintWithTag("VAD02", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	return binary.BigEndian.Uint32(authData[33:37]), nil
+}
+
+// parsePublicKey decodes a COSE_Key into a crypto public key
+func (w WebAuthn) parsePublicKey(coseKey []byte) (crypto.PublicKey, int, error) {
+	decoded, _, err := util.DecodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, cerror.NewAndPrintWithTag("PPK00", err, global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, cerror.NewAndPrintWithTag("PPK01", errors.New("cose key is not a map"), global.FRIENDLY_PASSKEY_INVALID)
+	}
+
+	alg, _ := key[int64(3)].(int64)
+	switch alg {
+	case COSE_ALG_ES256:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			break
+		}
+
+		publicKey := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			break
+		}
+		return publicKey, COSE_ALG_ES256, nil
+
+	case COSE_ALG_RS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			break
+		}
+
+		publicKey := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		return publicKey, COSE_ALG_RS256, nil
+
+	case COSE_ALG_EDDSA:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			break
+		}
+		return ed25519.PublicKey(x), COSE_ALG_EDDSA, nil
+	}
+
+	errMsg := fmt.Sprintf("unsupported or malformed cose key with alg %d", alg)
+	return nil, 0, cerror.NewAndPrintWithTag("PPK02", errors.New(errMsg), global.FRIENDLY_PASSKEY_INVALID)
+}
+
+// WebAuthnSignCountValid reports whether the sign count of an assertion
+// moved past the stored one, authenticators without a counter always
+// report zero
+func WebAuthnSignCountValid(stored, reported uint32) bool {
+	if stored == 0 && reported == 0 {
+		return true
+	}
+	return reported > stored
+}
+
+// EncodeWebAuthnID encodes binary ids the way the browser api expects them
+func EncodeWebAuthnID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+func DecodeWebAuthnID(id string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(id)
+}
@@ -0,0 +1,444 @@
+package helper
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+const (
+	testRPID   = "mymoment.localdev.info"
+	testOrigin = "http://mymoment.localdev.info"
+)
+
+// cborPairs is a map encoded in the given order
+type cborPairs [][2]interface{}
+
+// encodeCBOR covers the types a software authenticator needs
+func encodeCBOR(v interface{}) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= math.MaxUint8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= math.MaxUint16:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		default:
+			b := []byte{major<<5 | 26, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(b[1:], uint32(n))
+			return b
+		}
+	}
+
+	switch value := v.(type) {
+	case int:
+		if value < 0 {
+			return head(1, uint64(-1-value))
+		}
+		return head(0, uint64(value))
+	case []byte:
+		return append(head(2, uint64(len(value))), value...)
+	case string:
+		return append(head(3, uint64(len(value))), value...)
+	case cborPairs:
+		out := head(5, uint64(len(value)))
+		for _, pair := range value {
+			out = append(out, encodeCBOR(pair[0])...)
+			out = append(out, encodeCBOR(pair[1])...)
+		}
+		return out
+	}
+	panic("unsupported cbor value")
+}
+
+// softAuthenticator plays the authenticator and the browser of a ceremony
+type softAuthenticator struct {
+	alg          int
+	rpID         string
+	origin       string
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	ecKey        *ecdsa.PrivateKey
+	edKey        ed25519.PrivateKey
+}
+
+func newSoftAuthenticator(t *testing.T, alg int) *softAuthenticator {
+	a := &softAuthenticator{
+		alg:          alg,
+		rpID:         testRPID,
+		origin:       testOrigin,
+		flags:        webAuthnFlagUserPresent | webAuthnFlagUserVerified,
+		credentialID: make([]byte, 16),
+	}
+	_, _ = rand.Read(a.credentialID)
+
+	var err error
+	switch alg {
+	case COSE_ALG_ES256:
+		a.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case COSE_ALG_EDDSA:
+		_, a.edKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	if a.alg == COSE_ALG_EDDSA {
+		return encodeCBOR(cborPairs{{1, 1}, {3, COSE_ALG_EDDSA}, {-1, 6}, {-2, []byte(a.edKey.Public().(ed25519.PublicKey))}})
+	}
+
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.ecKey.X.FillBytes(x)
+	a.ecKey.Y.FillBytes(y)
+	return encodeCBOR(cborPairs{{1, 2}, {3, COSE_ALG_ES256}, {-1, 1}, {-2, x}, {-3, y}})
+}
+
+func (a *softAuthenticator) authenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	authData := append([]byte(nil), rpIDHash[:]...)
+
+	flags := a.flags
+	if attested {
+		flags |= webAuthnFlagAttested
+	}
+	authData = append(authData, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], a.signCount)
+
+	if attested {
+		authData = append(authData, make([]byte, 16)...) //aaguid
+		authData = append(authData, byte(len(a.credentialID)>>8), byte(len(a.credentialID)))
+		authData = append(authData, a.credentialID...)
+		authData = append(authData, a.coseKey()...)
+	}
+
+	return authData
+}
+
+func (a *softAuthenticator) clientData(ceremony string) []byte {
+	clientDataJSON, _ := json.Marshal(webAuthnClientData{
+		Type:      ceremony,
+		Challenge: "Y2hhbGxlbmdl",
+		Origin:    a.origin,
+	})
+	return clientDataJSON
+}
+
+func (a *softAuthenticator) attestationObject() []byte {
+	return encodeCBOR(cborPairs{
+		{"fmt", "none"},
+		{"attStmt", cborPairs{}},
+		{"authData", a.authenticatorData(true)},
+	})
+}
+
+// assertion returns authenticator data, client data and signature
+func (a *softAuthenticator) assertion() ([]byte, []byte, []byte) {
+	authData := a.authenticatorData(false)
+	clientDataJSON := a.clientData(WEBAUTHN_CEREMONY_GET)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+
+	if a.alg == COSE_ALG_EDDSA {
+		return authData, clientDataJSON, ed25519.Sign(a.edKey, signed)
+	}
+
+	digest := sha256.Sum256(signed)
+	signature, _ := ecdsa.SignASN1(rand.Reader, a.ecKey, digest[:])
+	return authData, clientDataJSON, signature
+}
+
+func testWebAuthn() WebAuthn {
+	return WebAuthn{rpID: testRPID, rpName: "MyMoment", origin: testOrigin}
+}
+
+var testAlgorithms = map[string]int{"ES256": COSE_ALG_ES256, "EdDSA": COSE_ALG_EDDSA}
+
+func TestVerifyClientData(t *testing.T) {
+	w := testWebAuthn()
+	a := newSoftAuthenticator(t, COSE_ALG_ES256)
+
+	wrongOrigin := newSoftAuthenticator(t, COSE_ALG_ES256)
+	wrongOrigin.origin = "http://evil.localdev.info"
+
+	tests := []struct {
+		name       string
+		clientData []byte
+		ceremony   string
+		wantErr    bool
+	}{
+		{"valid create", a.clientData(WEBAUTHN_CEREMONY_CREATE), WEBAUTHN_CEREMONY_CREATE, false},
+		{"valid get", a.clientData(WEBAUTHN_CEREMONY_GET), WEBAUTHN_CEREMONY_GET, false},
+		{"wrong ceremony", a.clientData(WEBAUTHN_CEREMONY_GET), WEBAUTHN_CEREMONY_CREATE, true},
+		{"wrong origin", wrongOrigin.clientData(WEBAUTHN_CEREMONY_GET), WEBAUTHN_CEREMONY_GET, true},
+		{"malformed json", []byte(`{"type":`), WEBAUTHN_CEREMONY_GET, true},
+		{"empty", nil, WEBAUTHN_CEREMONY_GET, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, err := w.VerifyClientData(tt.clientData, tt.ceremony)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && challenge != "Y2hhbGxlbmdl" {
+				t.Errorf("challenge = %q", challenge)
+			}
+		})
+	}
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	w := testWebAuthn()
+
+	for algName, alg := range testAlgorithms {
+		tests := []struct {
+			name    string
+			modify  func(a *softAuthenticator)
+			wantErr bool
+		}{
+			{"valid", func(a *softAuthenticator) {}, false},
+			{"wrong rp id hash", func(a *softAuthenticator) { a.rpID = "evil.localdev.info" }, true},
+			{"missing user present", func(a *softAuthenticator) { a.flags &^= webAuthnFlagUserPresent }, true},
+			{"missing user verified", func(a *softAuthenticator) { a.flags &^= webAuthnFlagUserVerified }, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(algName+"/"+tt.name, func(t *testing.T) {
+				a := newSoftAuthenticator(t, alg)
+				a.signCount = 3
+				tt.modify(a)
+
+				credential, err := w.VerifyAttestation(a.attestationObject())
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+
+				if !bytes.Equal(credential.CredentialID, a.credentialID) {
+					t.Errorf("credential id = %x, want %x", credential.CredentialID, a.credentialID)
+				}
+				if !bytes.Equal(credential.PublicKey, a.coseKey()) {
+					t.Errorf("public key = %x, want %x", credential.PublicKey, a.coseKey())
+				}
+				if credential.SignCount != 3 {
+					t.Errorf("sign count = %d, want 3", credential.SignCount)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyAttestationWithoutAttestedData(t *testing.T) {
+	w := testWebAuthn()
+	a := newSoftAuthenticator(t, COSE_ALG_ES256)
+
+	attestationObject := encodeCBOR(cborPairs{
+		{"fmt", "none"},
+		{"attStmt", cborPairs{}},
+		{"authData", a.authenticatorData(false)},
+	})
+	if _, err := w.VerifyAttestation(attestationObject); err == nil {
+		t.Fatal("attestation without the attested flag is accepted")
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	w := testWebAuthn()
+
+	for algName, alg := range testAlgorithms {
+		tests := []struct {
+			name            string
+			modify          func(a *softAuthenticator)
+			tamper          func(authData, clientData, signature []byte) ([]byte, []byte, []byte)
+			storedSignCount uint32
+			wantErr         bool
+			wantCountValid  bool
+		}{
+			{name: "valid", storedSignCount: 4, wantCountValid: true},
+			{name: "counter-less authenticator", modify: func(a *softAuthenticator) { a.signCount = 0 }, storedSignCount: 0, wantCountValid: true},
+			{name: "regressed sign count", modify: func(a *softAuthenticator) { a.signCount = 2 }, storedSignCount: 4, wantCountValid: false},
+			{name: "repeated sign count", storedSignCount: 5, wantCountValid: false},
+			{name: "wrong rp id hash", modify: func(a *softAuthenticator) { a.rpID = "evil.localdev.info" }, wantErr: true},
+			{name: "missing user present", modify: func(a *softAuthenticator) { a.flags &^= webAuthnFlagUserPresent }, wantErr: true},
+			{name: "missing user verified", modify: func(a *softAuthenticator) { a.flags &^= webAuthnFlagUserVerified }, wantErr: true},
+			{
+				name: "tampered client data",
+				tamper: func(authData, clientData, signature []byte) ([]byte, []byte, []byte) {
+					return authData, append(clientData[:len(clientData)-1:len(clientData)-1], ' ', '}'), signature
+				},
+				wantErr: true,
+			},
+			{
+				name: "tampered sign count",
+				tamper: func(authData, clientData, signature []byte) ([]byte, []byte, []byte) {
+					authData = append([]byte(nil), authData...)
+					authData[36]++
+					return authData, clientData, signature
+				},
+				wantErr: true,
+			},
+			{
+				name: "truncated authenticator data",
+				tamper: func(authData, clientData, signature []byte) ([]byte, []byte, []byte) {
+					return authData[:36], clientData, signature
+				},
+				wantErr: true,
+			},
+			{
+				name: "empty signature",
+				tamper: func(authData, clientData, signature []byte) ([]byte, []byte, []byte) {
+					return authData, clientData, nil
+				},
+				wantErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(algName+"/"+tt.name, func(t *testing.T) {
+				a := newSoftAuthenticator(t, alg)
+				publicKey := a.coseKey()
+
+				a.signCount = 5
+				if tt.modify != nil {
+					tt.modify(a)
+				}
+
+				authData, clientData, signature := a.assertion()
+				if tt.tamper != nil {
+					authData, clientData, signature = tt.tamper(authData, clientData, signature)
+				}
+
+				signCount, err := w.VerifyAssertion(publicKey, authData, clientData, signature)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+
+				if signCount != a.signCount {
+					t.Errorf("sign count = %d, want %d", signCount, a.signCount)
+				}
+				if valid := WebAuthnSignCountValid(tt.storedSignCount, signCount); valid != tt.wantCountValid {
+					t.Errorf("sign count %d after %d valid = %v, want %v", signCount, tt.storedSignCount, valid, tt.wantCountValid)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyAssertionWithOtherKey(t *testing.T) {
+	w := testWebAuthn()
+	for algName, alg := range testAlgorithms {
+		t.Run(algName, func(t *testing.T) {
+			owner := newSoftAuthenticator(t, alg)
+			other := newSoftAuthenticator(t, alg)
+
+			authData, clientData, signature := other.assertion()
+			if _, err := w.VerifyAssertion(owner.coseKey(), authData, clientData, signature); err == nil {
+				t.Fatal("assertion signed by another key is accepted")
+			}
+		})
+	}
+}
+
+func TestWebAuthnSignCountValid(t *testing.T) {
+	tests := []struct {
+		stored, reported uint32
+		want             bool
+	}{
+		{0, 0, true},
+		{0, 1, true},
+		{4, 5, true},
+		{5, 5, false},
+		{5, 4, false},
+		{5, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := WebAuthnSignCountValid(tt.stored, tt.reported); got != tt.want {
+			t.Errorf("WebAuthnSignCountValid(%d, %d) = %v, want %v", tt.stored, tt.reported, got, tt.want)
+		}
+	}
+}
+
+// every malformed input has to be rejected with an error, never a panic
+func TestWebAuthnMalformedInput(t *testing.T) {
+	w := testWebAuthn()
+	a := newSoftAuthenticator(t, COSE_ALG_ES256)
+	attestationObject := a.attestationObject()
+	authData, clientData, signature := a.assertion()
+
+	for i := 0; i < len(attestationObject); i++ {
+		if _, err := w.VerifyAttestation(attestationObject[:i]); err == nil {
+			t.Errorf("attestation object truncated to %d bytes is accepted", i)
+		}
+	}
+
+	coseKey := a.coseKey()
+	for i := 0; i < len(coseKey); i++ {
+		if _, err := w.VerifyAssertion(coseKey[:i], authData, clientData, signature); err == nil {
+			t.Errorf("public key truncated to %d bytes is accepted", i)
+		}
+	}
+
+	//the credential id length claims more bytes than there are
+	overflow := a.authenticatorData(true)
+	overflow[53], overflow[54] = 0x03, 0xff
+
+	tests := []struct {
+		name              string
+		attestationObject []byte
+	}{
+		{"not a map", encodeCBOR("authData")},
+		{"auth data is text", encodeCBOR(cborPairs{{"authData", "text"}})},
+		{"auth data too short", encodeCBOR(cborPairs{{"authData", make([]byte, 10)}})},
+		{"credential id overflow", encodeCBOR(cborPairs{{"authData", overflow}})},
+		{"indefinite length map", []byte{0xbf, 0x61, 'a', 0x01, 0xff}},
+		{"deep nesting", append(bytes.Repeat([]byte{0x81}, 64), 0x00)},
+		{"huge map length", []byte{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge byte string", []byte{0xa1, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := w.VerifyAttestation(tt.attestationObject); err == nil {
+				t.Fatal("malformed attestation object is accepted")
+			}
+		})
+	}
+
+	badKeys := map[string][]byte{
+		"unknown alg":       encodeCBOR(cborPairs{{1, 2}, {3, -36}}),
+		"point off curve":   encodeCBOR(cborPairs{{1, 2}, {3, COSE_ALG_ES256}, {-1, 1}, {-2, make([]byte, 32)}, {-3, make([]byte, 32)}}),
+		"short ed25519":     encodeCBOR(cborPairs{{1, 1}, {3, COSE_ALG_EDDSA}, {-1, 6}, {-2, make([]byte, 31)}}),
+		"wrong ed25519 crv": encodeCBOR(cborPairs{{1, 1}, {3, COSE_ALG_EDDSA}, {-1, 1}, {-2, make([]byte, 32)}}),
+		"short rsa modulus": encodeCBOR(cborPairs{{1, 3}, {3, COSE_ALG_RS256}, {-1, make([]byte, 128)}, {-2, []byte{1, 0, 1}}}),
+	}
+	for name, key := range badKeys {
+		t.Run(name, func(t *testing.T) {
+			if _, err := w.VerifyAssertion(key, authData, clientData, signature); err == nil {
+				t.Fatal("malformed public key is accepted")
+			}
+		})
+	}
+}
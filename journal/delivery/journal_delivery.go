@@ -0,0 +1,364 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	validator "github.com/go-playground/validator/v10"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type InsertJournalRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+type JournalResponse struct {
+	Message []string        `json:"message"`
+	Journal *domain.Journal `json:"journal,omitempty"`
+}
+
+type JournalListResponse struct {
+	Message     string           `json:"message"`
+	JournalList []domain.Journal `json:"journal_list"`
+}
+
+type DeleteJournalRequest struct {
+	JournalID string `json:"journal_id" binding:"required"`
+}
+
+type InviteMemberRequest struct {
+	JournalID string `json:"journal_id" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Role      string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type InviteMemberResponse struct {
+	Message    []string                  `json:"message"`
+	Invitation *domain.JournalInvitation `json:"invitation,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type MemberListRequest struct {
+	JournalID string `form:"journal_id" binding:"required"`
+}
+
+type MemberListResponse struct {
+	Message    string          `json:"message"`
+	MemberList []MemberElement `json:"member_list"`
+}
+
+type MemberElement struct {
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+type UpdateMemberRoleRequest struct {
+	JournalID string `json:"journal_id" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Role      string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type RemoveMemberRequest struct {
+	JournalID string `json:"journal_id" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+}
+
+/* #endregion */
+
+type JournalHandler struct {
+	useCase domain.IJournalUsecase
+}
+
+func NewJournalHandler(router *gin.Engine, journalUsecase domain.IJournalUsecase) {
+	handler := &JournalHandler{
+		useCase: journalUsecase,
+	}
+
+	router.POST("/api/journal", handler.InsertJournal)
+	router.GET("/api/journal", handler.JournalList)
+	router.POST("/api/journal/delete", handler.DeleteJournal)
+	router.POST("/api/journal/invite", handler.InviteMember)
+	router.POST("/api/journal/accept", handler.AcceptInvitation)
+	router.GET("/api/journal/member", handler.MemberList)
+	router.POST("/api/journal/member/role", handler.UpdateMemberRole)
+	router.POST("/api/journal/member/remove", handler.RemoveMember)
+}
+
+func (jh JournalHandler) InsertJournal(c *gin.Context) {
+	var (
+		request   InsertJournalRequest
+		response  JournalResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("IJH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	p := bluemonday.UGCPolicy()
+
+	var journal domain.Journal
+	journal.Name = p.Sanitize(request.Name)
+	journal.Description = p.Sanitize(request.Description)
+
+	storedJournal, err := jh.useCase.CreateJournal(journal, accountID)
+	if err != nil {
+		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Journal = storedJournal
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (jh JournalHandler) JournalList(c *gin.Context) {
+	var (
+		response  JournalListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	journalList, err := jh.useCase.JournalList(accountID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.JournalList = journalList
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (jh JournalHandler) DeleteJournal(c *gin.Context) {
+	var (
+		request   DeleteJournalRequest
+		response  JournalResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("DJH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = jh.useCase.DeleteJournal(request.JournalID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (jh JournalHandler) InviteMember(c *gin.Context) {
+	var (
+		request   InviteMemberRequest
+		response  InviteMemberResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("IVH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	invitation, err := jh.useCase.InviteMember(request.JournalID, accountID, request.Email, request.Role)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	response.Invitation = invitation
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (jh JournalHandler) AcceptInvitation(c *gin.Context) {
+	var (
+		request   AcceptInvitationRequest
+		response  JournalResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("AIH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	journal, err := jh.useCase.AcceptInvitation(request.Token, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	response.Journal = journal
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (jh JournalHandler) MemberList(c *gin.Context) {
+	var (
+		request   MemberListRequest
+		response  MemberListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("MLH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	memberList, err := jh.useCase.MemberList(request.JournalID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	response.MemberList = []MemberElement{}
+	for _, member := range memberList {
+		response.MemberList = append(response.MemberList, MemberElement{
+			Username:  member.Profile.Username,
+			FullName:  member.Profile.FullName,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt.Format(global.TIME_ISO8601),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (jh JournalHandler) UpdateMemberRole(c *gin.Context) {
+	var (
+		request   UpdateMemberRoleRequest
+		response  JournalResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("UMH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = jh.useCase.UpdateMemberRole(request.JournalID, accountID, request.Username, request.Role)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (jh JournalHandler) RemoveMember(c *gin.Context) {
+	var (
+		request   RemoveMemberRequest
+		response  JournalResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		response.Message = jh.validationMessage("RMH00", err, &request)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = jh.useCase.RemoveMember(request.JournalID, accountID, request.Username)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(jh.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (jh JournalHandler) validationMessage(tag string, err error, request interface{}) []string {
+	var messages []string
+	cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+
+	valError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{cerr.FriendlyMessageWithTag()}
+	}
+
+	for _, elem := range valError {
+		fieldName := elem.Field()
+		field, _ := reflect.TypeOf(request).Elem().FieldByName(fieldName)
+		jsonField, _ := field.Tag.Lookup("json")
+
+		switch elem.Tag() {
+		case "required":
+			msg := fmt.Sprintf(global.ERR_REQUIRED_FORMATTER, jsonField)
+			messages = append(messages, msg)
+			break
+
+		case "max":
+			msg := fmt.Sprintf(global.ERR_MAX_CHAR, jsonField, elem.Param())
+			messages = append(messages, msg)
+			break
+
+		case "email":
+			messages = append(messages, global.FRIENDLY_INVALID_EMAIL_FORMAT)
+			break
+
+		case "oneof":
+			msg := fmt.Sprintf(global.ERR_ONE_OF_FORMATTER, jsonField, elem.Param())
+			messages = append(messages, msg)
+			break
+		}
+	}
+
+	return messages
+}
+
+func (jh JournalHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case cerror.TYPE_UNAUTHORIZED:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
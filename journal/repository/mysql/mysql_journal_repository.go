@@ -0,0 +1,495 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+type MySqlJournalRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlJournalRepository(db *sql.DB) domain.IJournalRepository {
+	return &MySqlJournalRepository{
+		Db: db,
+	}
+}
+
+// InsertJournal stores the journal and its owner membership in one transaction
+func (jr MySqlJournalRepository) InsertJournal(journal domain.Journal, ownerID string) (*domain.Journal, error) {
+	if journal.JournalID == "" {
+		journal.JournalID = util.GenerateUUID()
+	}
+	journal.CreatedAt = time.Now()
+	journal.Role = domain.JOURNAL_ROLE_OWNER
+
+	/*start create query*/
+	journalQuery := sq.Insert("journal").
+		Columns("journal_id", "name", "description", "created_at").
+		Values(journal.JournalID, journal.Name, journal.Description, journal.CreatedAt)
+
+	journalSql, journalArgs, err := journalQuery.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	memberQuery := sq.Insert("journal_member").
+		Columns("journal_id", "account_id", "role", "created_at").
+		Values(journal.JournalID, ownerID, domain.JOURNAL_ROLE_OWNER, journal.CreatedAt)
+
+	memberSql, memberArgs, err := memberQuery.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJR01", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJR02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(journalSql, journalArgs...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("IJR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	_, err = tx.Exec(memberSql, memberArgs...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("IJR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJR05", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &journal, nil
+	/*end insert execution*/
+}
+
+func (jr MySqlJournalRepository) GetJournal(filter domain.JournalFilter) (*domain.Journal, error) {
+	sqlString, args, err := jr.journalQuery(filter).ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GJR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := jr.Db.QueryRow(sqlString, args...)
+
+	journal := new(domain.Journal)
+	err = row.Scan(&journal.JournalID, &journal.Name, &journal.Description, &journal.CreatedAt, &journal.Role)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GJR01", err, global.FRIENDLY_JOURNAL_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return journal, nil
+}
+
+func (jr MySqlJournalRepository) JournalList(filter domain.JournalFilter) ([]domain.Journal, error) {
+	query := jr.journalQuery(filter).
+		OrderBy("journal.created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("JLR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := jr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("JLR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	journalList := []domain.Journal{}
+	for rows.Next() {
+		var journal domain.Journal
+		err = rows.Scan(&journal.JournalID, &journal.Name, &journal.Description, &journal.CreatedAt, &journal.Role)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("JLR02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		journalList = append(journalList, journal)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("JLR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return journalList, nil
+}
+
+// DeleteJournal removes the journal with its members and invitations,
+// the posts are kept as personal posts of their authors
+func (jr MySqlJournalRepository) DeleteJournal(journalID string) error {
+	query := sq.Delete("journal").
+		Where(sq.Eq{"journal_id": journalID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DJR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DJR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (jr MySqlJournalRepository) GetMember(journalID, accountID string) (*domain.JournalMember, error) {
+	query := jr.memberQuery().
+		Where(sq.Eq{
+			"journal_member.journal_id": journalID,
+			"journal_member.account_id": accountID,
+		})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GJM00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := jr.Db.QueryRow(sqlString, args...)
+
+	member := new(domain.JournalMember)
+	err = jr.scanMember(row, member)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GJM01", err, global.FRIENDLY_JOURNAL_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return member, nil
+}
+
+func (jr MySqlJournalRepository) MemberList(journalID string) ([]domain.JournalMember, error) {
+	query := jr.memberQuery().
+		Where(sq.Eq{"journal_member.journal_id": journalID}).
+		OrderBy("journal_member.created_at ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("JML00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := jr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("JML01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	memberList := []domain.JournalMember{}
+	for rows.Next() {
+		var member domain.JournalMember
+		err = jr.scanMember(rows, &member)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("JML02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		memberList = append(memberList, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("JML03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return memberList, nil
+}
+
+func (jr MySqlJournalRepository) UpdateMemberRole(journalID, accountID, role string) error {
+	query := sq.Update("journal_member").
+		Set("role", role).
+		Where(sq.Eq{
+			"journal_id": journalID,
+			"account_id": accountID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UJM00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UJM01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UJM02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UJM03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UJM04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (jr MySqlJournalRepository) DeleteMember(journalID, accountID string) error {
+	query := sq.Delete("journal_member").
+		Where(sq.Eq{
+			"journal_id": journalID,
+			"account_id": accountID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJM00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJM01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DJM02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DJM03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DJM04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (jr MySqlJournalRepository) InsertInvitation(invitation domain.JournalInvitation) (*domain.JournalInvitation, error) {
+	if invitation.InvitationID == "" {
+		invitation.InvitationID = util.GenerateUUID()
+	}
+	invitation.CreatedAt = time.Now()
+
+	/*start create query*/
+	query := sq.Insert("journal_invitation").
+		Columns("invitation_id", "journal_id", "email", "role", "token_hash", "invited_by", "expires_at", "created_at").
+		Values(invitation.InvitationID, invitation.JournalID, invitation.Email, invitation.Role,
+			invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt, invitation.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJI00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJI01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("IJI02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("IJI03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IJI04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &invitation, nil
+	/*end insert execution*/
+}
+
+func (jr MySqlJournalRepository) GetInvitation(tokenHash string) (*domain.JournalInvitation, error) {
+	query := sq.Select("invitation_id, journal_id, email, role, token_hash, invited_by, expires_at, created_at").
+		From("journal_invitation").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GJI00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := jr.Db.QueryRow(sqlString, args...)
+
+	invitation := new(domain.JournalInvitation)
+	err = row.Scan(
+		&invitation.InvitationID,
+		&invitation.JournalID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GJI01", err, global.FRIENDLY_INVITATION_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation consumes the invitation and adds the member in one transaction,
+// only the request that deletes the invitation row is allowed to join
+func (jr MySqlJournalRepository) AcceptInvitation(invitation domain.JournalInvitation, accountID string) error {
+	/*start create query*/
+	deleteQuery := sq.Delete("journal_invitation").
+		Where(sq.Eq{"invitation_id": invitation.InvitationID}).
+		Where(sq.Gt{"expires_at": time.Now()})
+
+	deleteSql, deleteArgs, err := deleteQuery.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("AJI00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	//an existing member keeps their current role
+	memberQuery := sq.Insert("journal_member").
+		Options("IGNORE").
+		Columns("journal_id", "account_id", "role", "created_at").
+		Values(invitation.JournalID, accountID, invitation.Role, time.Now())
+
+	memberSql, memberArgs, err := memberQuery.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("AJI01", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	tx, err := jr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("AJI02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	result, err := tx.Exec(deleteSql, deleteArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("AJI03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("AJI04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if affected == 0 {
+		tx.Rollback()
+		cerr := cerror.NewAndPrintWithTag("AJI05", errors.New("invitation is already used or expired"), global.FRIENDLY_INVITATION_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return cerr
+	}
+
+	_, err = tx.Exec(memberSql, memberArgs...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("AJI06", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("AJI07", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+// journalQuery selects journals with the role of filter.AccountID,
+// journals the account is not a member of are excluded
+func (jr MySqlJournalRepository) journalQuery(filter domain.JournalFilter) sq.SelectBuilder {
+	query := sq.Select("journal.journal_id, journal.name, IFNULL(journal.description, ''), journal.created_at, journal_member.role").
+		From("journal").
+		Join("journal_member ON journal_member.journal_id = journal.journal_id").
+		Where(sq.Eq{"journal_member.account_id": filter.AccountID})
+
+	if filter.JournalID != "" {
+		query = query.Where(sq.Eq{"journal.journal_id": filter.JournalID})
+	}
+
+	return query
+}
+
+func (jr MySqlJournalRepository) memberQuery() sq.SelectBuilder {
+	return sq.Select(`journal_member.journal_id, journal_member.account_id, journal_member.role, journal_member.created_at,
+		IFNULL(profile.username, ''), IFNULL(profile.full_name, '')`).
+		From("journal_member").
+		LeftJoin("profile ON profile.account_id = journal_member.account_id")
+}
+
+func (jr MySqlJournalRepository) scanMember(row interface{ Scan(...interface{}) error }, member *domain.JournalMember) error {
+	return row.Scan(
+		&member.JournalID,
+		&member.AccountID,
+		&member.Role,
+		&member.CreatedAt,
+		&member.Profile.Username,
+		&member.Profile.FullName,
+	)
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	INVITATION_TOKEN_BYTES = 32
+	INVITATION_EXPIRY      = 7 * 24 * time.Hour
+)
+
+type JournalUsecase struct {
+	journalRepo domain.IJournalRepository
+	accountRepo domain.IAccountRepository
+	profileRepo domain.IProfileRepository
+	mailHelper  helper.IEMail
+}
+
+func NewJournalUsecase(journalRepository domain.IJournalRepository,
+	accountRepository domain.IAccountRepository,
+	profileRepository domain.IProfileRepository,
+	_mailHelper helper.IEMail) domain.IJournalUsecase {
+	return &JournalUsecase{
+		journalRepo: journalRepository,
+		accountRepo: accountRepository,
+		profileRepo: profileRepository,
+		mailHelper:  _mailHelper,
+	}
+}
+
+func (uc JournalUsecase) CreateJournal(journal domain.Journal, accountID string) (*domain.Journal, error) {
+	return uc.journalRepo.InsertJournal(journal, accountID)
+}
+
+func (uc JournalUsecase) JournalList(accountID string) ([]domain.Journal, error) {
+	filter := domain.JournalFilter{AccountID: accountID}
+	return uc.journalRepo.JournalList(filter)
+}
+
+func (uc JournalUsecase) DeleteJournal(journalID, accountID string) error {
+	_, err := uc.getJournal(journalID, accountID, domain.JOURNAL_ROLE_OWNER)
+	if err != nil {
+		return err
+	}
+
+	return uc.journalRepo.DeleteJournal(journalID)
+}
+
+// InviteMember emails a one time invitation link, only the hash of the
+// token is stored
+func (uc JournalUsecase) InviteMember(journalID, accountID, email, role string) (*domain.JournalInvitation, error) {
+	journal, err := uc.getJournal(journalID, accountID, domain.JOURNAL_ROLE_OWNER)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := domain.JournalInvitation{
+		JournalID: journalID,
+		Email:     email,
+		Role:      role,
+		InvitedBy: accountID,
+		ExpiresAt: time.Now().Add(INVITATION_EXPIRY),
+	}
+
+	invitation.Token, err = util.GenerateToken(INVITATION_TOKEN_BYTES)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("IMJ00", err, global.FRIENDLY_MESSAGE)
+	}
+	invitation.TokenHash = util.HashToken(invitation.Token)
+
+	storedInvitation, err := uc.journalRepo.InsertInvitation(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf(global.JOURNAL_INVITATION_TEMPLATE, journal.Name, uc.generateInvitationUrl(invitation.Token))
+	to := []string{email}
+	subject := config.Config.JournalInvitation.Subject
+	err = uc.mailHelper.SendMail(to, subject, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedInvitation, nil
+}
+
+func (uc JournalUsecase) AcceptInvitation(token, accountID string) (*domain.Journal, error) {
+	invitation, err := uc.journalRepo.GetInvitation(util.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.ExpiresAt.Before(time.Now()) {
+		cerr := cerror.NewAndPrintWithTag("AIJ00", errors.New("invitation is expired"), global.FRIENDLY_INVITATION_NOT_FOUND)
+		cerr.Type = cerror.TYPE_NOT_FOUND
+		return nil, cerr
+	}
+
+	//the invitation can only be used by the invited email
+	account, err := uc.accountRepo.GetAccount(domain.AccountFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(account.Email, invitation.Email) {
+		errMsg := fmt.Sprintf("invitation for %s used by account %s", invitation.Email, accountID)
+		cerr := cerror.NewAndPrintWithTag("AIJ01", errors.New(errMsg), global.FRIENDLY_INVITATION_EMAIL)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	err = uc.journalRepo.AcceptInvitation(*invitation, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := domain.JournalFilter{JournalID: invitation.JournalID, AccountID: accountID}
+	return uc.journalRepo.GetJournal(filter)
+}
+
+func (uc JournalUsecase) MemberList(journalID, accountID string) ([]domain.JournalMember, error) {
+	_, err := uc.getJournal(journalID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.journalRepo.MemberList(journalID)
+}
+
+func (uc JournalUsecase) UpdateMemberRole(journalID, accountID, username, role string) error {
+	member, err := uc.getManagedMember(journalID, accountID, username)
+	if err != nil {
+		return err
+	}
+
+	return uc.journalRepo.UpdateMemberRole(journalID, member.AccountID, role)
+}
+
+// RemoveMember lets the owner remove any other member,
+// other members can only remove themselves to leave the journal
+func (uc JournalUsecase) RemoveMember(journalID, accountID, username string) error {
+	profile, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if profile.AccountID == accountID {
+		member, err := uc.journalRepo.GetMember(journalID, accountID)
+		if err != nil {
+			return err
+		}
+
+		if member.Role == domain.JOURNAL_ROLE_OWNER {
+			cerr := cerror.NewAndPrintWithTag("RMJ00", errors.New("owner tried to leave the journal"), global.FRIENDLY_JOURNAL_OWNER)
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+			return cerr
+		}
+
+		return uc.journalRepo.DeleteMember(journalID, accountID)
+	}
+
+	member, err := uc.getManagedMember(journalID, accountID, username)
+	if err != nil {
+		return err
+	}
+
+	return uc.journalRepo.DeleteMember(journalID, member.AccountID)
+}
+
+// getManagedMember returns the member identified by username when accountID
+// owns the journal, the owner membership itself cannot be managed
+func (uc JournalUsecase) getManagedMember(journalID, accountID, username string) (*domain.JournalMember, error) {
+	_, err := uc.getJournal(journalID, accountID, domain.JOURNAL_ROLE_OWNER)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := uc.journalRepo.GetMember(journalID, profile.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Role == domain.JOURNAL_ROLE_OWNER {
+		cerr := cerror.NewAndPrintWithTag("GMM00", errors.New("owner membership cannot be managed"), global.FRIENDLY_JOURNAL_OWNER)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	return member, nil
+}
+
+// getJournal returns the journal when accountID is a member of it, roles
+// restricts the allowed roles of the member when given
+func (uc JournalUsecase) getJournal(journalID, accountID string, roles ...string) (*domain.Journal, error) {
+	filter := domain.JournalFilter{JournalID: journalID, AccountID: accountID}
+	journal, err := uc.journalRepo.GetJournal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roles) == 0 {
+		return journal, nil
+	}
+
+	for _, role := range roles {
+		if journal.Role == role {
+			return journal, nil
+		}
+	}
+
+	errMsg := fmt.Sprintf("account %s with role %s is not allowed in journal %s", accountID, journal.Role, journalID)
+	cerr := cerror.NewAndPrintWithTag("GJU00", errors.New(errMsg), global.FRIENDLY_JOURNAL_FORBIDDEN)
+	cerr.Type = cerror.TYPE_UNAUTHORIZED
+	return nil, cerr
+}
+
+func (uc JournalUsecase) getProfileByUsername(username string) (*domain.Profile, error) {
+	filter := domain.ProfileFilter{Username: username}
+	profile, err := uc.profileRepo.GetProfile(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_PROFILE_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (uc JournalUsecase) generateInvitationUrl(token string) string {
+	url := fmt.Sprintf("%s/journal_invitation?token=%s", config.Config.FEHost, token)
+	return url
+}
@@ -29,6 +29,40 @@ import (
 	_imageDelivery "github.com/pajri/personal-backend/image/delivery"
 	_imageRepository "github.com/pajri/personal-backend/image/repository/mysql"
 	_imageUsecase "github.com/pajri/personal-backend/image/usecase"
+
+	_collectionDelivery "github.com/pajri/personal-backend/collection/delivery"
+	_collectionRepository "github.com/pajri/personal-backend/collection/repository/mysql"
+	_collectionUsecase "github.com/pajri/personal-backend/collection/usecase"
+
+	_shareDelivery "github.com/pajri/personal-backend/share/delivery"
+	_shareRepository "github.com/pajri/personal-backend/share/repository/mysql"
+	_shareUsecase "github.com/pajri/personal-backend/share/usecase"
+
+	_followDelivery "github.com/pajri/personal-backend/follow/delivery"
+	_followRepository "github.com/pajri/personal-backend/follow/repository/mysql"
+	_followUsecase "github.com/pajri/personal-backend/follow/usecase"
+
+	_commentDelivery "github.com/pajri/personal-backend/comment/delivery"
+	_commentRepository "github.com/pajri/personal-backend/comment/repository/mysql"
+	_commentUsecase "github.com/pajri/personal-backend/comment/usecase"
+
+	_reactionDelivery "github.com/pajri/personal-backend/reaction/delivery"
+	_reactionRepository "github.com/pajri/personal-backend/reaction/repository/mysql"
+	_reactionUsecase "github.com/pajri/personal-backend/reaction/usecase"
+
+	_journalDelivery "github.com/pajri/personal-backend/journal/delivery"
+	_journalRepository "github.com/pajri/personal-backend/journal/repository/mysql"
+	_journalUsecase "github.com/pajri/personal-backend/journal/usecase"
+
+	_notificationDelivery "github.com/pajri/personal-backend/notification/delivery"
+	_notificationRepository "github.com/pajri/personal-backend/notification/repository/mysql"
+	_notificationUsecase "github.com/pajri/personal-backend/notification/usecase"
+	_notificationWorker "github.com/pajri/personal-backend/notification/worker"
+
+	_streamDelivery "github.com/pajri/personal-backend/stream/delivery"
+	_streamUsecase "github.com/pajri/personal-backend/stream/usecase"
+
+	_syndicationDelivery "github.com/pajri/personal-backend/syndication/delivery"
 )
 
 func main() {
@@ -70,39 +104,86 @@ func main() {
 
 	/*start init redis*/
 	helper.InitRedis()
-	defer helper.RedisHelper.(helper.Redis).Client.Close()
+	defer helper.RedisHelper.(helper.Redis).Pool.Close()
 	/*end init redis*/
 
+	helper.InitJWTKeys()
+
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{config.Config.FEHost},
-		AllowMethods:     []string{"GET", "POST"},
+		AllowMethods:     []string{"GET", "POST", "DELETE"},
 		AllowCredentials: true,
 	}))
 	r.Static("/upload/images/", "./upload/images")
 
 	//setup helper
 	mailHelper := helper.NewEmailHelper()
+	webAuthnHelper := helper.NewWebAuthnHelper()
+	oidcHelper := helper.NewOIDCHelper()
+	passwordPolicy := helper.NewPasswordPolicyHelper()
+	markdownHelper := helper.NewMarkdownHelper()
 
 	//setup repo and usecase
+	streamUsecase := _streamUsecase.NewStreamUsecase(helper.RedisHelper)
+
 	imageRepo := _imageRepository.NewMySqlImageRepository(dbConn)
 	imageUsecase := _imageUsecase.NewImageUsecase(imageRepo)
 
+	profileRepo := _profileRepository.NewMySqlProfileRepository(dbConn)
+	restrictionRepo := _profileRepository.NewMySqlRestrictionRepository(dbConn)
+
+	reactionRepo := _reactionRepository.NewMySqlReactionRepository(dbConn)
+	journalRepo := _journalRepository.NewMySqlJournalRepository(dbConn)
+
 	postRepo := _postRepository.NewMySqlPostRepository(dbConn)
-	postUsecase := _postUsecase.NewPostUseCase(postRepo, imageRepo)
+	postUsecase := _postUsecase.NewPostUseCase(postRepo, imageRepo, profileRepo, reactionRepo, journalRepo, streamUsecase, markdownHelper)
+
+	collectionRepo := _collectionRepository.NewMySqlCollectionRepository(dbConn)
+	collectionUsecase := _collectionUsecase.NewCollectionUsecase(collectionRepo, postRepo)
+
+	notificationRepo := _notificationRepository.NewMySqlNotificationRepository(dbConn)
+	notificationUsecase := _notificationUsecase.NewNotificationUsecase(notificationRepo, postRepo, streamUsecase)
+
+	shareLinkRepo := _shareRepository.NewMySqlShareLinkRepository(dbConn)
+	shareLinkUsecase := _shareUsecase.NewShareLinkUsecase(shareLinkRepo, postRepo, notificationUsecase)
+
+	followRepo := _followRepository.NewMySqlFollowRepository(dbConn)
+	followUsecase := _followUsecase.NewFollowUsecase(followRepo, profileRepo, postRepo, reactionRepo, restrictionRepo, notificationUsecase)
+
+	commentRepo := _commentRepository.NewMySqlCommentRepository(dbConn)
+	commentUsecase := _commentUsecase.NewCommentUsecase(commentRepo, postRepo, notificationUsecase)
+
+	reactionUsecase := _reactionUsecase.NewReactionUsecase(reactionRepo, postRepo, notificationUsecase)
 
 	accountRepo := _accountRepository.NewMySqlAccountRepository(dbConn)
 
-	profileRepo := _profileRepository.NewMySqlProfileRepository(dbConn)
-	profileUsecase := _profileUsecase.NewProfileUsecase(accountRepo, profileRepo)
+	profileUsecase := _profileUsecase.NewProfileUsecase(accountRepo, profileRepo, restrictionRepo, followRepo)
+
+	journalUsecase := _journalUsecase.NewJournalUsecase(journalRepo, accountRepo, profileRepo, mailHelper)
 
-	authUsecase := _authUsecase.NewAuthUsecase(accountRepo, profileRepo, mailHelper)
+	twoFactorRepo := _accountRepository.NewMySqlTwoFactorRepository(dbConn)
+	passkeyRepo := _accountRepository.NewMySqlPasskeyRepository(dbConn)
+	identityRepo := _accountRepository.NewMySqlAccountIdentityRepository(dbConn)
+	sessionRepo := _accountRepository.NewMySqlSessionRepository(dbConn)
+	authUsecase := _authUsecase.NewAuthUsecase(accountRepo, profileRepo, twoFactorRepo, passkeyRepo, identityRepo, sessionRepo, mailHelper, webAuthnHelper, oidcHelper, passwordPolicy)
 
 	r.Use(middleware.Middleware(authUsecase))
 	_postDelivery.NewPostHandler(r, postUsecase)
 	_authDelivery.NewAuthHandler(r, authUsecase)
 	_imageDelivery.NewImageHandler(r, imageUsecase)
 	_profileDelivery.NewProfileHandler(r, profileUsecase)
+	_collectionDelivery.NewCollectionHandler(r, collectionUsecase)
+	_shareDelivery.NewShareLinkHandler(r, shareLinkUsecase)
+	_followDelivery.NewFollowHandler(r, followUsecase)
+	_commentDelivery.NewCommentHandler(r, commentUsecase)
+	_reactionDelivery.NewReactionHandler(r, reactionUsecase)
+	_journalDelivery.NewJournalHandler(r, journalUsecase)
+	_notificationDelivery.NewNotificationHandler(r, notificationUsecase)
+	_streamDelivery.NewStreamHandler(r, streamUsecase)
+	_syndicationDelivery.NewSyndicationHandler(r, postUsecase, profileUsecase)
+
+	_notificationWorker.StartDailyJobs(notificationUsecase)
 
 	r.Run(":5000")
 
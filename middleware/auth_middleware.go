@@ -28,7 +28,7 @@ func handleAuth(c *gin.Context, useCase domain.IAuthUsecase) bool {
 
 			/*start parse jwt*/
 			jwtHelper := helper.JWTHelper{}
-			parsedToken, err := jwtHelper.ParseToken(token)
+			parsedToken, err := jwtHelper.ParseToken(token, helper.TOKEN_USE_ACCESS)
 			if err != nil {
 				cerr, ok := err.(cerror.Error)
 				if !ok {
@@ -73,8 +73,17 @@ func handleAuth(c *gin.Context, useCase domain.IAuthUsecase) bool {
 				return false
 			}
 
+			//tokens issued before sessions were recorded have no session id
+			sessionID, _ := claims["session_id"].(string)
+
 			c.Set("account_id", accountID)
 			c.Set("email", email)
+			c.Set("session_id", sessionID)
+
+			//a stream ticket ends with the access token it was issued for
+			exp, _ := claims["exp"].(float64)
+			c.Set("access_uuid", claims["access_uuid"].(string))
+			c.Set("access_exp", int64(exp))
 		} else {
 			_ = cerror.New("AUM02", errors.New("token_not_found"), "token_not_found") //only need to print the error
 			resp := AuthResponse{
@@ -12,6 +12,21 @@ var excludedFromAuth = []string{
 	"/api/auth/reset_password/",
 	"/api/auth/change_password",
 	"/api/auth/refresh_token",
+	"/api/auth/2fa/verify",
+	"/api/auth/passkey/login/begin",
+	"/api/auth/passkey/login/finish",
+	"/api/auth/oidc/begin",
+	"/api/auth/oidc/callback",
+	"/api/auth/magic_link",
+	"/api/auth/magic_link/login",
+	"/api/shared/:token",
+	"/api/u/:username/posts",
+	"/api/u/:username/posts/:post_id",
+	"/u/:username/feed.atom",
+	"/u/:username/feed.rss",
+	"/u/:username/feed.json",
+	"/.well-known/jwks.json",
+	"/api/stream",
 }
 
 func Middleware(authUseCase domain.IAuthUsecase) gin.HandlerFunc {
@@ -0,0 +1,158 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type NotificationListRequest struct {
+	Date  time.Time `form:"date"`
+	Limit uint64    `form:"limit"`
+}
+
+type NotificationListResponse struct {
+	Message          string                `json:"message"`
+	UnreadCount      int                   `json:"unread_count"`
+	NotificationList []NotificationElement `json:"notification_list"`
+}
+
+type NotificationElement struct {
+	NotificationID string             `json:"notification_id"`
+	Type           string             `json:"type"`
+	Actor          *NotificationActor `json:"actor,omitempty"`
+	PostID         string             `json:"post_id,omitempty"`
+	IsRead         bool               `json:"is_read"`
+	Date           string             `json:"date"`
+	HiddenDate     string             `json:"hidden_date"`
+}
+
+type NotificationActor struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+type MarkReadRequest struct {
+	NotificationID string `json:"notification_id" binding:"required"`
+}
+
+type MarkReadResponse struct {
+	Message []string `json:"message"`
+}
+
+/* #endregion */
+
+type NotificationHandler struct {
+	useCase domain.INotificationUsecase
+}
+
+func NewNotificationHandler(router *gin.Engine, notificationUsecase domain.INotificationUsecase) {
+	handler := &NotificationHandler{
+		useCase: notificationUsecase,
+	}
+
+	router.GET("/api/notifications", handler.NotificationList)
+	router.POST("/api/notifications/read", handler.MarkRead)
+	router.POST("/api/notifications/read_all", handler.MarkAllRead)
+}
+
+func (nh NotificationHandler) NotificationList(c *gin.Context) {
+	var (
+		request   NotificationListRequest
+		response  NotificationListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("NLH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	filter := domain.NotificationFilter{
+		AccountID: accountID,
+		Date:      request.Date,
+		Limit:     request.Limit,
+	}
+	notificationList, unreadCount, err := nh.useCase.NotificationList(filter)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.UnreadCount = unreadCount
+	response.NotificationList = []NotificationElement{}
+	for _, notification := range notificationList {
+		response.NotificationList = append(response.NotificationList, nh.createNotificationElement(notification))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (nh NotificationHandler) MarkRead(c *gin.Context) {
+	var (
+		request   MarkReadRequest
+		response  MarkReadResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("MRH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = nh.useCase.MarkRead(request.NotificationID, accountID)
+	if err != nil {
+		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (nh NotificationHandler) MarkAllRead(c *gin.Context) {
+	var (
+		response  MarkReadResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := nh.useCase.MarkAllRead(accountID)
+	if err != nil {
+		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (nh NotificationHandler) createNotificationElement(notification domain.Notification) NotificationElement {
+	var element NotificationElement
+	element.NotificationID = notification.NotificationID
+	element.Type = notification.Type
+	element.PostID = notification.PostID
+	element.IsRead = notification.IsRead
+	element.Date = notification.CreatedAt.Format(global.TIME_FORMAT)
+	element.HiddenDate = notification.CreatedAt.Format(global.TIME_ISO8601)
+	if notification.ActorID != "" {
+		element.Actor = &NotificationActor{
+			Username: notification.Actor.Username,
+			FullName: notification.Actor.FullName,
+		}
+	}
+
+	return element
+}
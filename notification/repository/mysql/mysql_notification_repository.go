@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+type MySqlNotificationRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlNotificationRepository(db *sql.DB) domain.INotificationRepository {
+	return &MySqlNotificationRepository{
+		Db: db,
+	}
+}
+
+func (nr MySqlNotificationRepository) InsertNotification(notification domain.Notification) error {
+	if notification.NotificationID == "" {
+		notification.NotificationID = util.GenerateUUID()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	var actorID, postID interface{}
+	if notification.ActorID != "" {
+		actorID = notification.ActorID
+	}
+	if notification.PostID != "" {
+		postID = notification.PostID
+	}
+
+	/*start create query*/
+	query := sq.Insert("notification").
+		Columns("notification_id", "account_id", "actor_id", "type", "post_id", "created_at").
+		Values(notification.NotificationID, notification.AccountID, actorID, notification.Type, postID, notification.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("INR00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := nr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("INR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("INR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("INR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("INR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (nr MySqlNotificationRepository) NotificationList(filter domain.NotificationFilter) ([]domain.Notification, error) {
+	query := sq.Select(`notification.notification_id, notification.account_id, IFNULL(notification.actor_id, ''),
+		notification.type, IFNULL(notification.post_id, ''), notification.is_read, notification.created_at,
+		IFNULL(profile.username, ''), IFNULL(profile.full_name, '')`).
+		From("notification").
+		LeftJoin("profile ON profile.account_id = notification.actor_id").
+		Where(sq.Eq{"notification.account_id": filter.AccountID}).
+		OrderBy("notification.created_at DESC")
+
+	var zeroTime time.Time
+	if filter.Date != zeroTime {
+		query = query.Where(sq.Lt{"notification.created_at": filter.Date})
+	}
+
+	if filter.Limit != 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("NLR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := nr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("NLR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	notificationList := []domain.Notification{}
+	for rows.Next() {
+		var notification domain.Notification
+		err = rows.Scan(
+			&notification.NotificationID,
+			&notification.AccountID,
+			&notification.ActorID,
+			&notification.Type,
+			&notification.PostID,
+			&notification.IsRead,
+			&notification.CreatedAt,
+			&notification.Actor.Username,
+			&notification.Actor.FullName,
+		)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("NLR02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		notificationList = append(notificationList, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("NLR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return notificationList, nil
+}
+
+func (nr MySqlNotificationRepository) UnreadCount(accountID string) (int, error) {
+	query := sq.Select("COUNT(*)").
+		From("notification").
+		Where(sq.Eq{
+			"account_id": accountID,
+			"is_read":    false,
+		})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("UCN00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var count int
+	err = nr.Db.QueryRow(sqlString, args...).Scan(&count)
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("UCN01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return count, nil
+}
+
+func (nr MySqlNotificationRepository) MarkRead(notificationID, accountID string) error {
+	query := sq.Update("notification").
+		Set("is_read", true).
+		Where(sq.Eq{
+			"notification_id": notificationID,
+			"account_id":      accountID,
+		})
+
+	return nr.update("MRN", query)
+}
+
+func (nr MySqlNotificationRepository) MarkAllRead(accountID string) error {
+	query := sq.Update("notification").
+		Set("is_read", true).
+		Where(sq.Eq{
+			"account_id": accountID,
+			"is_read":    false,
+		})
+
+	return nr.update("MAN", query)
+}
+
+func (nr MySqlNotificationRepository) DeleteNotificationBefore(date time.Time) (int64, error) {
+	query := sq.Delete("notification").
+		Where(sq.Lt{"created_at": date})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("DNB00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	result, err := nr.Db.Exec(sql, args...)
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("DNB01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, cerror.NewAndPrintWithTag("DNB02", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return deleted, nil
+}
+
+func (nr MySqlNotificationRepository) update(tag string, query sq.UpdateBuilder) error {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := nr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag(tag+"02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag(tag+"03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag(tag+"04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
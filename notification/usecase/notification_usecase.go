@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"log"
+	"time"
+
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	NOTIFICATION_RETENTION = 90 * 24 * time.Hour
+
+	//the lock outlives the day so a late instance still sees it
+	MEMORY_LOCK_PREFIX = "notify_memory:"
+	MEMORY_LOCK_EXPIRY = 48 * time.Hour
+)
+
+type NotificationUsecase struct {
+	notificationRepo domain.INotificationRepository
+	postRepo         domain.IPostRepository
+	publisher        domain.IEventPublisher
+}
+
+func NewNotificationUsecase(notificationRepository domain.INotificationRepository,
+	postRepository domain.IPostRepository,
+	publisher domain.IEventPublisher) domain.INotificationUsecase {
+	return &NotificationUsecase{
+		notificationRepo: notificationRepository,
+		postRepo:         postRepository,
+		publisher:        publisher,
+	}
+}
+
+// Notify stores the notification, accounts are never notified about their own actions
+func (uc NotificationUsecase) Notify(notification domain.Notification) {
+	if notification.AccountID == "" || notification.AccountID == notification.ActorID {
+		return
+	}
+
+	notification.NotificationID = util.GenerateUUID()
+	notification.CreatedAt = time.Now()
+
+	//the repository already prints the error
+	err := uc.notificationRepo.InsertNotification(notification)
+	if err != nil {
+		return
+	}
+
+	event := domain.Event{Type: domain.EVENT_NOTIFICATION, Data: notification}
+	uc.publisher.Publish(event, notification.AccountID)
+}
+
+func (uc NotificationUsecase) NotificationList(filter domain.NotificationFilter) ([]domain.Notification, int, error) {
+	notificationList, err := uc.notificationRepo.NotificationList(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unreadCount, err := uc.notificationRepo.UnreadCount(filter.AccountID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notificationList, unreadCount, nil
+}
+
+func (uc NotificationUsecase) MarkRead(notificationID, accountID string) error {
+	return uc.notificationRepo.MarkRead(notificationID, accountID)
+}
+
+func (uc NotificationUsecase) MarkAllRead(accountID string) error {
+	return uc.notificationRepo.MarkAllRead(accountID)
+}
+
+func (uc NotificationUsecase) PruneNotification() error {
+	deleted, err := uc.notificationRepo.DeleteNotificationBefore(time.Now().Add(-NOTIFICATION_RETENTION))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[PNU00] pruned %d notifications\n", deleted)
+	return nil
+}
+
+// NotifyMemory reminds every account of one post written on the same day in
+// previous years, the latest of them is picked. Every instance runs it, the
+// redis lock lets only the first run of the day notify
+func (uc NotificationUsecase) NotifyMemory(date time.Time) error {
+	key := MEMORY_LOCK_PREFIX + date.Format("2006-01-02")
+	acquired, err := helper.RedisHelper.SetIfAbsent(key, time.Now().Unix(), time.Now().Add(MEMORY_LOCK_EXPIRY).Unix())
+	if err != nil || !acquired {
+		return err
+	}
+
+	postList, err := uc.postRepo.MemoryList(date)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range postList {
+		uc.Notify(domain.Notification{
+			AccountID: post.AccountID,
+			Type:      domain.NOTIFICATION_MEMORY,
+			PostID:    post.PostID,
+		})
+	}
+
+	return nil
+}
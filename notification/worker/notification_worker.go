@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/pajri/personal-backend/domain"
+)
+
+// DAILY_JOB_HOUR is the local hour the daily jobs run at
+const DAILY_JOB_HOUR = 7
+
+// StartDailyJobs prunes old notifications and sends the memories of the day
+// every day at DAILY_JOB_HOUR. A start after that hour runs them at once,
+// NotifyMemory skips a day another run or instance already notified
+func StartDailyJobs(notificationUsecase domain.INotificationUsecase) {
+	go func() {
+		now := time.Now()
+		if !now.Before(dailyRunTime(now)) {
+			runDailyJobs(notificationUsecase, now)
+		}
+
+		for {
+			next := nextDailyRun(time.Now())
+			time.Sleep(time.Until(next))
+			runDailyJobs(notificationUsecase, next)
+		}
+	}()
+}
+
+func runDailyJobs(notificationUsecase domain.INotificationUsecase, date time.Time) {
+	//errors are already printed by the repository
+	_ = notificationUsecase.PruneNotification()
+	_ = notificationUsecase.NotifyMemory(date)
+}
+
+func dailyRunTime(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, DAILY_JOB_HOUR, 0, 0, 0, date.Location())
+}
+
+// nextDailyRun is the first run time after now
+func nextDailyRun(now time.Time) time.Time {
+	next := dailyRunTime(now)
+	if !next.After(now) {
+		next = dailyRunTime(now.AddDate(0, 0, 1))
+	}
+	return next
+}
@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	validator "github.com/go-playground/validator/v10"
-	"github.com/microcosm-cc/bluemonday"
 	"github.com/pajri/personal-backend/adapter/cerror"
 	"github.com/pajri/personal-backend/domain"
 	"github.com/pajri/personal-backend/global"
@@ -22,8 +23,15 @@ type InsertPostResponse struct {
 }
 
 type InsertPostRequest struct {
-	Content  string `form:"content" binding:"required"`
-	ImageURL string `form:"image_url"`
+	Content    string   `form:"content" binding:"required"`
+	ImageURL   string   `form:"image_url"`
+	Mood       int      `form:"mood" binding:"omitempty,min=1,max=5"`
+	PlaceName  string   `form:"place_name" binding:"max=255"`
+	Latitude   *float64 `form:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude  *float64 `form:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	Weather    string   `form:"weather" binding:"max=100"`
+	Visibility string   `form:"visibility" binding:"omitempty,oneof=private unlisted followers public"`
+	JournalID  string   `form:"journal_id"`
 }
 
 type DeletePostRequest struct {
@@ -34,26 +42,96 @@ type DeletePostResponse struct {
 	Message []string `json:"message"`
 }
 
-type PostListingRequest struct {
+type FavoritePostRequest struct {
+	PostID     string `json:"post_id" binding:"required"`
+	IsFavorite *bool  `json:"is_favorite" binding:"required"`
+}
+
+type PinPostRequest struct {
+	PostID   string `json:"post_id" binding:"required"`
+	IsPinned *bool  `json:"is_pinned" binding:"required"`
+}
+
+type PostVisibilityRequest struct {
+	PostID     string `json:"post_id" binding:"required"`
+	Visibility string `json:"visibility" binding:"required,oneof=private unlisted followers public"`
+}
+
+type PublicPostListingRequest struct {
 	Date  time.Time `form:"date"`
-	Limit uint64    `form:"limit"`
+	Limit uint64    `form:"limit" binding:"max=50"`
+}
+
+type PublicPostResponse struct {
+	Message string              `json:"message"`
+	Post    *PostListingElement `json:"post,omitempty"`
+}
+
+type UpdatePostFlagResponse struct {
+	Message []string `json:"message"`
+}
+
+type PostListingRequest struct {
+	Date         time.Time `form:"date"`
+	Limit        uint64    `form:"limit"`
+	Mood         int       `form:"mood" binding:"omitempty,min=1,max=5"`
+	BBox         string    `form:"bbox"`
+	CollectionID string    `form:"collection_id"`
+	JournalID    string    `form:"journal_id"`
+	FavoriteOnly bool      `form:"favorites_only"`
 }
 
 type PostListingResponse struct {
-	Message  string               `json:"message"`
-	PostList []PostListingElement `json:"post_list"`
+	Message        string               `json:"message"`
+	PinnedPostList []PostListingElement `json:"pinned_post_list,omitempty"`
+	PostList       []PostListingElement `json:"post_list"`
 }
 
 type PostListingElement struct {
-	PostID     string `json:"post_id"`
-	Content    string `json:"content"`
-	ImageURL   string `json:"image_url"`
-	Date       string `json:"date"`
-	HiddenDate string `json:"hidden_date"`
+	PostID       string                   `json:"post_id"`
+	Content      string                   `json:"content"`
+	ContentHTML  string                   `json:"content_html"`
+	ImageURL     string                   `json:"image_url"`
+	Mood         int                      `json:"mood,omitempty"`
+	MoodEmoji    string                   `json:"mood_emoji,omitempty"`
+	PlaceName    string                   `json:"place_name,omitempty"`
+	Latitude     *float64                 `json:"latitude,omitempty"`
+	Longitude    *float64                 `json:"longitude,omitempty"`
+	Weather      string                   `json:"weather,omitempty"`
+	IsFavorite   bool                     `json:"is_favorite"`
+	IsPinned     bool                     `json:"is_pinned"`
+	Visibility   string                   `json:"visibility"`
+	JournalID    string                   `json:"journal_id,omitempty"`
+	CommentCount int                      `json:"comment_count"`
+	Reactions    []domain.ReactionSummary `json:"reactions"`
+	Date         string                   `json:"date"`
+	HiddenDate   string                   `json:"hidden_date"`
+}
+
+type PostMapRequest struct {
+	BBox string `form:"bbox" binding:"required"`
+	Zoom *int   `form:"zoom" binding:"required,min=0,max=22"`
+	Mood int    `form:"mood" binding:"omitempty,min=1,max=5"`
+}
+
+type PostMapResponse struct {
+	Message     string           `json:"message"`
+	ClusterList []PostMapElement `json:"cluster_list"`
+}
+
+type PostMapElement struct {
+	Geohash      string  `json:"geohash"`
+	Count        int     `json:"count"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	PostID       string  `json:"post_id"`
+	ThumbnailURL string  `json:"thumbnail_url"`
 }
 
 /* #endregion */
 
+const MAX_PUBLIC_LIMIT = 50
+
 type PostHandler struct {
 	useCase domain.IPostUsecase
 }
@@ -65,7 +143,16 @@ func NewPostHandler(router *gin.Engine, postUsecase domain.IPostUsecase) {
 
 	router.POST("/api/post", handler.InsertPost)
 	router.GET("/api/post", handler.PostListing)
+	router.GET("/api/post/map", handler.PostMap)
+	router.GET("/api/collection/:collection_id/post", handler.PostListing)
 	router.POST("/api/post/delete", handler.DeletePost)
+	router.POST("/api/post/favorite", handler.FavoritePost)
+	router.POST("/api/post/pin", handler.PinPost)
+	router.POST("/api/post/visibility", handler.SetVisibility)
+
+	//public routes, see excludedFromAuth
+	router.GET("/api/u/:username/posts", handler.PublicPostListing)
+	router.GET("/api/u/:username/posts/:post_id", handler.GetPublicPost)
 }
 
 func (ph PostHandler) InsertPost(c *gin.Context) {
@@ -79,8 +166,8 @@ func (ph PostHandler) InsertPost(c *gin.Context) {
 	if err != nil {
 		cerr := cerror.NewAndPrintWithTag("IPH00", err, global.FRIENDLY_MESSAGE)
 
-		valError := err.(validator.ValidationErrors)
-		if valError != nil {
+		valError, ok := err.(validator.ValidationErrors)
+		if ok {
 			for _, elem := range valError {
 				fieldName := elem.Field()
 				field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
@@ -91,6 +178,31 @@ func (ph PostHandler) InsertPost(c *gin.Context) {
 					msg := fmt.Sprintf(global.ERR_REQUIRED_FORMATTER, jsonField)
 					response.Message = append(response.Message, msg)
 					break
+
+				case "required_with":
+					otherField, _ := reflect.TypeOf(&request).Elem().FieldByName(elem.Param())
+					otherJsonField, _ := otherField.Tag.Lookup("form")
+					msg := fmt.Sprintf(global.ERR_REQUIRED_WITH_FORMATTER, jsonField, otherJsonField)
+					response.Message = append(response.Message, msg)
+					break
+
+				case "min":
+					msg := fmt.Sprintf(global.ERR_MIN_VALUE, jsonField, elem.Param())
+					response.Message = append(response.Message, msg)
+					break
+
+				case "max":
+					msg := fmt.Sprintf(global.ERR_MAX_VALUE, jsonField, elem.Param())
+					if elem.Kind() == reflect.String {
+						msg = fmt.Sprintf(global.ERR_MAX_CHAR, jsonField, elem.Param())
+					}
+					response.Message = append(response.Message, msg)
+					break
+
+				case "oneof":
+					msg := fmt.Sprintf(global.ERR_ONE_OF_FORMATTER, jsonField, elem.Param())
+					response.Message = append(response.Message, msg)
+					break
 				}
 			}
 
@@ -103,29 +215,32 @@ func (ph PostHandler) InsertPost(c *gin.Context) {
 		return
 	}
 
-	//sanitize input
-	p := bluemonday.UGCPolicy()
-	request.Content = p.Sanitize(request.Content)
-
 	var post domain.Post
 	post.Content = request.Content
 	post.ImageURL = request.ImageURL
+	post.Mood = request.Mood
+	post.PlaceName = request.PlaceName
+	post.Latitude = request.Latitude
+	post.Longitude = request.Longitude
+	post.Weather = request.Weather
+	post.Visibility = request.Visibility
+	post.JournalID = request.JournalID
 	post.AccountID = accountID
 
 	var storedPost *domain.Post
 	storedPost, err = ph.useCase.InsertPost(post)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
 
 	//the response will be used as first element of listing
 	//so the post response uses PostListingElement type
 	var postResponse PostListingElement
 	postResponse = ph.creatPostListingElement(*storedPost)
 
-	if err != nil {
-		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
-		c.JSON(http.StatusInternalServerError, response)
-		return
-	}
-
 	response = InsertPostResponse{nil, postResponse}
 	c.JSON(http.StatusCreated, response)
 	return
@@ -156,10 +271,65 @@ func (ph PostHandler) PostListing(c *gin.Context) {
 		return
 	}
 
-	postList, err := ph.useCase.PostListing(accountID, request.Limit, request.Date)
+	//journal timeline lists the posts of every member
+	var filter domain.PostFilter
+	filter.AccountID = accountID
+	filter.ViewerID = accountID
+	if request.JournalID != "" {
+		filter.AccountID = ""
+		filter.JournalID = request.JournalID
+	}
+	filter.Limit = request.Limit
+	filter.Date = request.Date
+	filter.Mood = request.Mood
+	filter.CollectionID = request.CollectionID
+	filter.FavoriteOnly = request.FavoriteOnly
+
+	//collection timeline passes the collection in the path
+	if c.Param("collection_id") != "" {
+		filter.CollectionID = c.Param("collection_id")
+	}
+
+	if request.BBox != "" {
+		filter.BoundingBox, err = ph.parseBoundingBox(request.BBox)
+		if err != nil {
+			cerr := cerror.NewAndPrintWithTag("PLD02", err, global.FRIENDLY_INVALID_BBOX)
+			response.Message = cerr.FriendlyMessageWithTag()
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	//pinned posts are listed separately on the first page only,
+	//so the date cursor of the timeline is not affected by them
+	var zeroTime time.Time
+	if request.Date == zeroTime {
+		pinned := true
+		pinnedFilter := filter
+		pinnedFilter.IsPinned = &pinned
+		pinnedFilter.Limit = 0
+
+		pinnedList, err := ph.useCase.PostListing(pinnedFilter)
+		if err != nil {
+			cerr := err.(cerror.Error)
+			response.Message = cerr.FriendlyMessageWithTag()
+			c.JSON(ph.errorStatus(cerr), response)
+			return
+		}
+
+		for _, post := range pinnedList {
+			response.PinnedPostList = append(response.PinnedPostList, ph.creatPostListingElement(post))
+		}
+	}
+
+	notPinned := false
+	filter.IsPinned = &notPinned
+
+	postList, err := ph.useCase.PostListing(filter)
 	if err != nil {
-		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
-		c.JSON(http.StatusInternalServerError, response)
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ph.errorStatus(cerr), response)
 		return
 	}
 
@@ -176,6 +346,55 @@ func (ph PostHandler) PostListing(c *gin.Context) {
 	return
 }
 
+func (ph PostHandler) PostMap(c *gin.Context) {
+	var (
+		request   PostMapRequest
+		response  PostMapResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("PMD00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var filter domain.PostFilter
+	filter.AccountID = accountID
+	filter.Mood = request.Mood
+	filter.BoundingBox, err = ph.parseBoundingBox(request.BBox)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("PMD01", err, global.FRIENDLY_INVALID_BBOX)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	clusterList, err := ph.useCase.PostMap(filter, *request.Zoom)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.ClusterList = []PostMapElement{}
+	for _, cluster := range clusterList {
+		response.ClusterList = append(response.ClusterList, PostMapElement{
+			Geohash:      cluster.Geohash,
+			Count:        cluster.Count,
+			Latitude:     cluster.Latitude,
+			Longitude:    cluster.Longitude,
+			PostID:       cluster.PostID,
+			ThumbnailURL: cluster.ImageURL,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
 func (ph PostHandler) DeletePost(c *gin.Context) {
 	var (
 		request   DeletePostRequest
@@ -211,21 +430,222 @@ func (ph PostHandler) DeletePost(c *gin.Context) {
 
 	err = ph.useCase.DeletePost(request.PostID, accountID)
 	if err != nil {
-		response.Message = []string{err.(cerror.Error).FriendlyMessageWithTag()}
-		c.JSON(http.StatusInternalServerError, response)
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ph PostHandler) FavoritePost(c *gin.Context) {
+	var (
+		request   FavoritePostRequest
+		response  UpdatePostFlagResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("FPH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ph.useCase.SetFavorite(request.PostID, accountID, *request.IsFavorite)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ph PostHandler) PinPost(c *gin.Context) {
+	var (
+		request   PinPostRequest
+		response  UpdatePostFlagResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("PPH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ph.useCase.SetPinned(request.PostID, accountID, *request.IsPinned)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ph PostHandler) SetVisibility(c *gin.Context) {
+	var (
+		request   PostVisibilityRequest
+		response  UpdatePostFlagResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("SVH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = ph.useCase.SetVisibility(request.PostID, accountID, request.Visibility)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+func (ph PostHandler) PublicPostListing(c *gin.Context) {
+	var (
+		request  PublicPostListingRequest
+		response PostListingResponse
+		username string = c.Param("username")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("PPL00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if request.Limit == 0 {
+		request.Limit = MAX_PUBLIC_LIMIT
+	}
+
+	postList, err := ph.useCase.PublicPostListing(username, request.Limit, request.Date)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	for _, post := range postList {
+		response.PostList = append(response.PostList, ph.createPublicPostListingElement(post))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (ph PostHandler) GetPublicPost(c *gin.Context) {
+	var response PublicPostResponse
+
+	post, err := ph.useCase.GetPublicPost(c.Param("username"), c.Param("post_id"))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	element := ph.createPublicPostListingElement(*post)
+	response.Post = &element
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+// createPublicPostListingElement leaves out flags that only matter to the
+// owner, and the location which would tell strangers where the owner lives
+func (ph PostHandler) createPublicPostListingElement(post domain.Post) PostListingElement {
+	post.IsFavorite = false
+	post.IsPinned = false
+	post.PlaceName = ""
+	post.Latitude = nil
+	post.Longitude = nil
+	return ph.creatPostListingElement(post)
+}
+
+func (ph PostHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case cerror.TYPE_UNAUTHORIZED:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
 func (ph PostHandler) creatPostListingElement(post domain.Post) PostListingElement {
 	var postListingElement PostListingElement
 	postListingElement.PostID = post.PostID
 	postListingElement.Content = post.Content
+	postListingElement.ContentHTML = post.ContentHTML
 	postListingElement.ImageURL = post.ImageURL
+	postListingElement.Mood = post.Mood
+	postListingElement.MoodEmoji = domain.MoodEmoji[post.Mood]
+	postListingElement.PlaceName = post.PlaceName
+	postListingElement.Latitude = post.Latitude
+	postListingElement.Longitude = post.Longitude
+	postListingElement.Weather = post.Weather
+	postListingElement.IsFavorite = post.IsFavorite
+	postListingElement.IsPinned = post.IsPinned
+	postListingElement.Visibility = post.Visibility
+	postListingElement.JournalID = post.JournalID
+	postListingElement.CommentCount = post.CommentCount
+	postListingElement.Reactions = post.Reactions
+	if postListingElement.Reactions == nil {
+		postListingElement.Reactions = []domain.ReactionSummary{}
+	}
 	postListingElement.Date = post.Date.Format(global.TIME_FORMAT)
 	postListingElement.HiddenDate = post.Date.Format(global.TIME_ISO8601)
 
 	return postListingElement
 }
+
+// parseBoundingBox parses bbox in min_lon,min_lat,max_lon,max_lat order
+func (ph PostHandler) parseBoundingBox(bbox string) (*domain.BoundingBox, error) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox %s must have 4 values", bbox)
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	box := &domain.BoundingBox{
+		MinLon: values[0],
+		MinLat: values[1],
+		MaxLon: values[2],
+		MaxLat: values[3],
+	}
+
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat ||
+		box.MinLon < -180 || box.MinLon > 180 || box.MaxLon < -180 || box.MaxLon > 180 {
+		return nil, fmt.Errorf("bbox %s is out of range", bbox)
+	}
+
+	return box, nil
+}
@@ -23,14 +23,61 @@ type MySqlPostRepository struct {
 	Db *sql.DB
 }
 
+const postColumns = `post_id, content, IFNULL(content_html, ''), image_url,
+	IFNULL(mood, 0), IFNULL(place_name, ''), latitude, longitude, IFNULL(weather, ''), is_favorite, is_pinned, visibility,
+	(SELECT COUNT(*) FROM comment WHERE comment.post_id = post.post_id), date, IFNULL(last_updated, date), account_id, IFNULL(journal_id, '')`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPost(row rowScanner, post *domain.Post) error {
+	return row.Scan(
+		&post.PostID,
+		&post.Content,
+		&post.ContentHTML,
+		&post.ImageURL,
+		&post.Mood,
+		&post.PlaceName,
+		&post.Latitude,
+		&post.Longitude,
+		&post.Weather,
+		&post.IsFavorite,
+		&post.IsPinned,
+		&post.Visibility,
+		&post.CommentCount,
+		&post.Date,
+		&post.LastUpdated,
+		&post.AccountID,
+		&post.JournalID,
+	)
+}
+
+// nullIfEmpty keeps optional columns NULL instead of storing zero values
+func nullIfEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case int:
+		if v == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
 func (ur MySqlPostRepository) InsertPost(post domain.Post) (*domain.Post, error) {
 	if post.PostID == "" {
 		post.PostID = util.GenerateUUID()
 	}
 	/*start create query*/
 	query := sq.Insert("post").
-		Columns("post_id", "content", "image_url", "date", "last_updated", "account_id").
-		Values(post.PostID, post.Content, post.ImageURL, post.Date, time.Now(), post.AccountID)
+		Columns("post_id", "content", "content_html", "image_url", "mood", "place_name",
+			"latitude", "longitude", "weather", "geohash", "visibility", "date", "last_updated", "account_id", "journal_id").
+		Values(post.PostID, post.Content, post.ContentHTML, post.ImageURL, nullIfEmpty(post.Mood), nullIfEmpty(post.PlaceName),
+			post.Latitude, post.Longitude, nullIfEmpty(post.Weather), nullIfEmpty(post.Geohash), post.Visibility, post.Date, time.Now(), post.AccountID, nullIfEmpty(post.JournalID))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -68,7 +115,7 @@ func (ur MySqlPostRepository) InsertPost(post domain.Post) (*domain.Post, error)
 }
 
 func (ur MySqlPostRepository) PostList(filter domain.PostFilter) ([]domain.Post, error) {
-	query := sq.Select("post_id, content, image_url, date").
+	query := sq.Select(postColumns).
 		From("post").
 		OrderBy("date DESC")
 
@@ -85,6 +132,52 @@ func (ur MySqlPostRepository) PostList(filter domain.PostFilter) ([]domain.Post,
 		query = query.Where(sq.Lt{"date": filter.Date})
 	}
 
+	if filter.Mood != domain.MOOD_NONE {
+		query = query.Where(sq.Eq{"mood": filter.Mood})
+	}
+
+	if filter.BoundingBox != nil {
+		query = query.Where(boundingBoxCondition(*filter.BoundingBox))
+	}
+
+	if filter.FavoriteOnly {
+		query = query.Where(sq.Eq{"is_favorite": true})
+	}
+
+	if filter.IsPinned != nil {
+		query = query.Where(sq.Eq{"is_pinned": *filter.IsPinned})
+	}
+
+	if len(filter.Visibility) > 0 {
+		query = query.Where(sq.Eq{"visibility": filter.Visibility})
+	}
+
+	if len(filter.AccountIDs) > 0 {
+		query = query.Where(sq.Eq{"account_id": filter.AccountIDs})
+	}
+
+	//posts of every account followed by FollowerID
+	if filter.FollowerID != "" {
+		query = query.Where("account_id IN (SELECT followee_id FROM follow WHERE follower_id = ? AND status = ?)",
+			filter.FollowerID, domain.FOLLOW_STATUS_ACCEPTED)
+	}
+
+	if filter.CollectionID != "" {
+		query = query.Where("post_id IN (SELECT post_id FROM collection_post WHERE collection_id = ?)", filter.CollectionID)
+	}
+
+	if filter.JournalID != "" {
+		query = query.Where(sq.Eq{"journal_id": filter.JournalID})
+	}
+
+	if filter.ViewerID != "" {
+		query = query.Where(viewerCondition(filter.ViewerID))
+	}
+
+	if filter.RestrictedBy != "" {
+		query = query.Where("account_id NOT IN (SELECT target_id FROM account_restriction WHERE account_id = ?)", filter.RestrictedBy)
+	}
+
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, cerror.NewAndPrintWithTag("PLI00", err, global.FRIENDLY_MESSAGE)
@@ -102,7 +195,7 @@ func (ur MySqlPostRepository) PostList(filter domain.PostFilter) ([]domain.Post,
 	var postList []domain.Post
 	for rows.Next() {
 		var post domain.Post
-		err = rows.Scan(&post.PostID, &post.Content, &post.ImageURL, &post.Date)
+		err = scanPost(rows, &post)
 		if err != nil {
 			return nil, cerror.NewAndPrintWithTag("PLI02", err, global.FRIENDLY_MESSAGE)
 		}
@@ -119,13 +212,25 @@ func (ur MySqlPostRepository) PostList(filter domain.PostFilter) ([]domain.Post,
 }
 
 func (ur MySqlPostRepository) GetPost(filter domain.PostFilter) (*domain.Post, error) {
-	query := sq.Select("post_id, content, image_url, date").
+	query := sq.Select(postColumns).
 		From("post")
 
 	if filter.PostID != "" {
 		query = query.Where(sq.Eq{"post_id": filter.PostID})
 	}
 
+	if filter.AccountID != "" {
+		query = query.Where(sq.Eq{"account_id": filter.AccountID})
+	}
+
+	if len(filter.Visibility) > 0 {
+		query = query.Where(sq.Eq{"visibility": filter.Visibility})
+	}
+
+	if filter.ViewerID != "" {
+		query = query.Where(viewerCondition(filter.ViewerID))
+	}
+
 	sqlString, args, err := query.ToSql()
 	if err != nil {
 		return nil, cerror.NewAndPrintWithTag("GPR00", err, global.FRIENDLY_MESSAGE)
@@ -137,7 +242,7 @@ func (ur MySqlPostRepository) GetPost(filter domain.PostFilter) (*domain.Post, e
 	}
 
 	post := new(domain.Post)
-	err = row.Scan(&post.PostID, &post.Content, &post.ImageURL, &post.Date)
+	err = scanPost(row, post)
 	if err != nil {
 		return nil, cerror.NewAndPrintWithTag("GPR02", err, global.FRIENDLY_MESSAGE)
 	}
@@ -145,13 +250,10 @@ func (ur MySqlPostRepository) GetPost(filter domain.PostFilter) (*domain.Post, e
 	return post, nil
 }
 
-func (ur MySqlPostRepository) DeletePost(postID, accountID string) error {
+func (ur MySqlPostRepository) DeletePost(postID string) error {
 	/*start create query*/
 	query := sq.Delete("post").
-		Where(sq.Eq{
-			"post_id":    postID,
-			"account_id": accountID,
-		})
+		Where(sq.Eq{"post_id": postID})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -185,3 +287,250 @@ func (ur MySqlPostRepository) DeletePost(postID, accountID string) error {
 
 	return nil
 }
+
+func (ur MySqlPostRepository) PostClusterList(filter domain.PostFilter, precision int) ([]domain.PostCluster, error) {
+	//every post is ranked inside its cell so the latest post with image represents the cell
+	cellQuery := sq.Select("post_id", "image_url").
+		Column(sq.Expr("LEFT(geohash, ?) AS cell", precision)).
+		Column("COUNT(*) OVER cell_window AS total").
+		Column("AVG(latitude) OVER cell_window AS center_latitude").
+		Column("AVG(longitude) OVER cell_window AS center_longitude").
+		Column("ROW_NUMBER() OVER (cell_window ORDER BY IFNULL(image_url, '') = '', date DESC) AS rank_in_cell").
+		From("post").
+		Where(sq.NotEq{"geohash": nil}).
+		Suffix("WINDOW cell_window AS (PARTITION BY LEFT(geohash, ?))", precision)
+
+	if filter.AccountID != "" {
+		cellQuery = cellQuery.Where(sq.Eq{"account_id": filter.AccountID})
+	}
+
+	if filter.Mood != domain.MOOD_NONE {
+		cellQuery = cellQuery.Where(sq.Eq{"mood": filter.Mood})
+	}
+
+	if filter.BoundingBox != nil {
+		cellQuery = cellQuery.Where(boundingBoxCondition(*filter.BoundingBox))
+	}
+
+	query := sq.Select("cell, total, center_latitude, center_longitude, post_id, IFNULL(image_url, '')").
+		FromSelect(cellQuery, "cell_post").
+		Where(sq.Eq{"rank_in_cell": 1})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PCL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := ur.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PCL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var clusterList []domain.PostCluster
+	for rows.Next() {
+		var cluster domain.PostCluster
+		err = rows.Scan(&cluster.Geohash, &cluster.Count, &cluster.Latitude,
+			&cluster.Longitude, &cluster.PostID, &cluster.ImageURL)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("PCL02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		clusterList = append(clusterList, cluster)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("PCL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return clusterList, nil
+}
+
+func (ur MySqlPostRepository) MemoryList(date time.Time) ([]domain.Post, error) {
+	year, month, day := date.Date()
+	memoryQuery := sq.Select("account_id", "post_id").
+		Column("ROW_NUMBER() OVER (PARTITION BY account_id ORDER BY date DESC) AS rank_in_account").
+		From("post").
+		Where("MONTH(date) = ? AND DAY(date) = ? AND YEAR(date) < ?", int(month), day, year)
+
+	query := sq.Select("account_id, post_id").
+		FromSelect(memoryQuery, "memory_post").
+		Where(sq.Eq{"rank_in_account": 1})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PML00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := ur.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("PML01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var postList []domain.Post
+	for rows.Next() {
+		var post domain.Post
+		err = rows.Scan(&post.AccountID, &post.PostID)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("PML02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		postList = append(postList, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("PML03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return postList, nil
+}
+
+func (ur MySqlPostRepository) UpdateFavorite(postID string, isFavorite bool) error {
+	query := sq.Update("post").
+		Set("is_favorite", isFavorite).
+		Where(sq.Eq{"post_id": postID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFV00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := ur.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFV01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sqlString)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UFV02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sqlString, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UFV03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UFV04", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
+
+func (ur MySqlPostRepository) UpdatePinned(postID string, isPinned bool) error {
+	query := sq.Update("post").
+		Set("is_pinned", isPinned).
+		Where(sq.Eq{"post_id": postID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPN00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := ur.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPN01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sqlString)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UPN02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sqlString, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UPN03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPN04", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
+
+func (ur MySqlPostRepository) UpdateVisibility(postID, visibility string) error {
+	query := sq.Update("post").
+		Set("visibility", visibility).
+		Set("last_updated", time.Now()).
+		Where(sq.Eq{"post_id": postID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UVS00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := ur.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UVS01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sqlString)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UVS02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sqlString, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UVS03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UVS04", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
+
+// viewerCondition matches the posts visible to viewerID, their own posts, posts of
+// journals they are a member of, public and unlisted posts, and followers only
+// posts of accounts they follow. Posts of accounts that blocked viewerID are excluded
+func viewerCondition(viewerID string) sq.Sqlizer {
+	return sq.And{
+		sq.Or{
+			sq.Eq{"account_id": viewerID},
+			sq.Expr("journal_id IN (SELECT journal_id FROM journal_member WHERE account_id = ?)", viewerID),
+			sq.Eq{"visibility": []string{domain.VISIBILITY_PUBLIC, domain.VISIBILITY_UNLISTED}},
+			sq.And{
+				sq.Eq{"visibility": domain.VISIBILITY_FOLLOWERS},
+				sq.Expr("account_id IN (SELECT followee_id FROM follow WHERE follower_id = ? AND status = ?)",
+					viewerID, domain.FOLLOW_STATUS_ACCEPTED),
+			},
+		},
+		sq.Expr("account_id NOT IN (SELECT account_id FROM account_restriction WHERE target_id = ? AND type = ?)",
+			viewerID, domain.RESTRICTION_BLOCK),
+	}
+}
+
+func boundingBoxCondition(box domain.BoundingBox) sq.Sqlizer {
+	latitude := sq.And{
+		sq.GtOrEq{"latitude": box.MinLat},
+		sq.LtOrEq{"latitude": box.MaxLat},
+	}
+
+	if box.MinLon <= box.MaxLon {
+		return append(latitude, sq.GtOrEq{"longitude": box.MinLon}, sq.LtOrEq{"longitude": box.MaxLon})
+	}
+
+	//box crosses the antimeridian
+	return append(latitude, sq.Or{
+		sq.GtOrEq{"longitude": box.MinLon},
+		sq.LtOrEq{"longitude": box.MaxLon},
+	})
+}
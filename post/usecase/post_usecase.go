@@ -1,59 +1,245 @@
 package usecase
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/pajri/personal-backend/adapter/cerror"
 	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	GEOHASH_PRECISION = 12
+	MAX_PINNED_POST   = 3
 )
 
 type PostUsecase struct {
-	postRepo  domain.IPostRepository
-	imageRepo domain.IImageRepository
+	postRepo       domain.IPostRepository
+	imageRepo      domain.IImageRepository
+	profileRepo    domain.IProfileRepository
+	reactionRepo   domain.IReactionRepository
+	journalRepo    domain.IJournalRepository
+	publisher      domain.IEventPublisher
+	markdownHelper helper.IMarkdown
 }
 
 func NewPostUseCase(postRepository domain.IPostRepository,
-	imageRepository domain.IImageRepository) *PostUsecase {
+	imageRepository domain.IImageRepository,
+	profileRepository domain.IProfileRepository,
+	reactionRepository domain.IReactionRepository,
+	journalRepository domain.IJournalRepository,
+	publisher domain.IEventPublisher,
+	_markdownHelper helper.IMarkdown) *PostUsecase {
 
 	return &PostUsecase{
-		postRepo:  postRepository,
-		imageRepo: imageRepository,
+		postRepo:       postRepository,
+		imageRepo:      imageRepository,
+		profileRepo:    profileRepository,
+		reactionRepo:   reactionRepository,
+		journalRepo:    journalRepository,
+		publisher:      publisher,
+		markdownHelper: _markdownHelper,
 	}
 }
 
 func (uc PostUsecase) InsertPost(post domain.Post) (*domain.Post, error) {
+	var err error
+	if post.JournalID != "" {
+		_, err = uc.getJournalMember(post.JournalID, post.AccountID, domain.JOURNAL_ROLE_OWNER, domain.JOURNAL_ROLE_EDITOR)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	post.Date = time.Now()
+	if post.Visibility == "" {
+		post.Visibility = domain.VISIBILITY_PRIVATE
+	}
+
+	//content is stored as markdown source, the rendered html is cached alongside it
+	post.ContentHTML, err = uc.markdownHelper.Render(post.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Latitude != nil && post.Longitude != nil {
+		post.Geohash = util.EncodeGeohash(*post.Latitude, *post.Longitude, GEOHASH_PRECISION)
+	}
+
 	newPost, err := uc.postRepo.InsertPost(post)
 	if err != nil {
 		return nil, err
 	}
 
+	uc.publishPost(domain.EVENT_POST_CREATED, *newPost)
+
 	return newPost, nil
 }
 
-func (uc PostUsecase) PostListing(accountID string, limit uint64, date time.Time) ([]domain.Post, error) {
-	var postList []domain.Post
+func (uc PostUsecase) PostListing(filter domain.PostFilter) ([]domain.Post, error) {
+	if filter.JournalID != "" {
+		_, err := uc.getJournalMember(filter.JournalID, filter.ViewerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	postList, err := uc.postRepo.PostList(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	//posts stored before markdown support have no cached html
+	for i := range postList {
+		if postList[i].ContentHTML == "" && postList[i].Content != "" {
+			postList[i].ContentHTML, err = uc.markdownHelper.Render(postList[i].Content)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = uc.attachReactions(postList, filter.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return postList, nil
+}
+
+func (uc PostUsecase) PostMap(filter domain.PostFilter, zoom int) ([]domain.PostCluster, error) {
+	return uc.postRepo.PostClusterList(filter, uc.geohashPrecision(zoom))
+}
+
+func (uc PostUsecase) SetFavorite(postID, accountID string, isFavorite bool) error {
+	post, err := uc.getEditablePost(postID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.postRepo.UpdateFavorite(postID, isFavorite)
+	if err != nil {
+		return err
+	}
+
+	uc.publishPost(domain.EVENT_POST_UPDATED, *post)
+	return nil
+}
+
+func (uc PostUsecase) SetPinned(postID, accountID string, isPinned bool) error {
+	post, err := uc.getEditablePost(postID, accountID)
+	if err != nil {
+		return err
+	}
+
+	//journal posts are pinned on the journal timeline
+	if isPinned && !post.IsPinned {
+		pinned := true
+		filter := domain.PostFilter{AccountID: post.AccountID, IsPinned: &pinned}
+		if post.JournalID != "" {
+			filter = domain.PostFilter{JournalID: post.JournalID, IsPinned: &pinned}
+		}
+		pinnedList, err := uc.postRepo.PostList(filter)
+		if err != nil {
+			return err
+		}
+
+		if len(pinnedList) >= MAX_PINNED_POST {
+			errMsg := fmt.Sprintf("account %s already has %d pinned posts", accountID, len(pinnedList))
+			cerr := cerror.NewAndPrintWithTag("SPU00", errors.New(errMsg), fmt.Sprintf(global.FRIENDLY_MAX_PINNED_POST, MAX_PINNED_POST))
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+			return cerr
+		}
+	}
+
+	err = uc.postRepo.UpdatePinned(postID, isPinned)
+	if err != nil {
+		return err
+	}
+
+	uc.publishPost(domain.EVENT_POST_UPDATED, *post)
+	return nil
+}
+
+func (uc PostUsecase) SetVisibility(postID, accountID, visibility string) error {
+	post, err := uc.getEditablePost(postID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.postRepo.UpdateVisibility(postID, visibility)
+	if err != nil {
+		return err
+	}
+
+	uc.publishPost(domain.EVENT_POST_UPDATED, *post)
+	return nil
+}
+
+func (uc PostUsecase) PublicPostListing(username string, limit uint64, date time.Time) ([]domain.Post, error) {
+	profile, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return nil, err
+	}
 
 	var filter domain.PostFilter
-	filter.AccountID = accountID
+	filter.AccountID = profile.AccountID
+	filter.Visibility = []string{domain.VISIBILITY_PUBLIC}
 	filter.Limit = limit
 	filter.Date = date
-	postList, err := uc.postRepo.PostList(filter)
-	return postList, err
+	return uc.PostListing(filter)
+}
+
+func (uc PostUsecase) GetPublicPost(username, postID string) (*domain.Post, error) {
+	profile, err := uc.getProfileByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	//unlisted posts are reachable by their id but never listed
+	filter := domain.PostFilter{
+		PostID:     postID,
+		AccountID:  profile.AccountID,
+		Visibility: []string{domain.VISIBILITY_PUBLIC, domain.VISIBILITY_UNLISTED},
+	}
+	post, err := uc.postRepo.GetPost(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	postList := []domain.Post{*post}
+	err = uc.attachReactions(postList, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &postList[0], nil
 }
 
 func (uc PostUsecase) DeletePost(postID, accountID string) error {
-	//get post data
-	postFilter := domain.PostFilter{PostID: postID}
-	post, err := uc.postRepo.GetPost(postFilter)
+	post, err := uc.getEditablePost(postID, accountID)
 	if err != nil {
 		return err
 	}
 
-	err = uc.postRepo.DeletePost(postID, accountID)
+	err = uc.postRepo.DeletePost(postID)
 	if err != nil {
 		return err
 	}
 
+	uc.publishPost(domain.EVENT_POST_DELETED, *post)
+
 	if post.ImageURL != "" {
 		image := domain.Image{ImageURL: post.ImageURL}
 		err = uc.imageRepo.DeleteImage(image, true)
@@ -64,3 +250,147 @@ func (uc PostUsecase) DeletePost(postID, accountID string) error {
 
 	return nil
 }
+
+// publishPost pushes the event to the author and, for journal posts, to
+// every journal member
+func (uc PostUsecase) publishPost(eventType string, post domain.Post) {
+	accountIDs := []string{post.AccountID}
+	if post.JournalID != "" {
+		memberList, err := uc.journalRepo.MemberList(post.JournalID)
+		if err != nil {
+			return
+		}
+
+		for _, member := range memberList {
+			accountIDs = append(accountIDs, member.AccountID)
+		}
+	}
+
+	event := domain.Event{
+		Type: eventType,
+		Data: domain.PostEvent{PostID: post.PostID, JournalID: post.JournalID},
+	}
+	uc.publisher.Publish(event, accountIDs...)
+}
+
+// geohashPrecision picks the geohash cell size that roughly matches
+// a map tile grid at the given web map zoom level
+func (uc PostUsecase) geohashPrecision(zoom int) int {
+	switch {
+	case zoom <= 2:
+		return 1
+	case zoom <= 5:
+		return 2
+	case zoom <= 7:
+		return 3
+	case zoom <= 10:
+		return 4
+	case zoom <= 12:
+		return 5
+	case zoom <= 15:
+		return 6
+	case zoom <= 17:
+		return 7
+	default:
+		//close enough to show every post on its own
+		return GEOHASH_PRECISION
+	}
+}
+
+// attachReactions fills the reaction summary of every post, viewerID is
+// used to tell which reactions belong to the viewing account
+func (uc PostUsecase) attachReactions(postList []domain.Post, viewerID string) error {
+	var postIDs []string
+	for _, post := range postList {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	summaries, err := uc.reactionRepo.ReactionSummaryList(postIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for i := range postList {
+		postList[i].Reactions = summaries[postList[i].PostID]
+	}
+
+	return nil
+}
+
+// getEditablePost returns the post when accountID is allowed to change it. Personal
+// posts belong to their author, journal posts can be changed by the journal owner
+// and by editors who wrote them
+func (uc PostUsecase) getEditablePost(postID, accountID string) (*domain.Post, error) {
+	filter := domain.PostFilter{PostID: postID, ViewerID: accountID}
+	post, err := uc.postRepo.GetPost(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	if post.JournalID == "" {
+		if post.AccountID == accountID {
+			return post, nil
+		}
+	} else {
+		member, err := uc.getJournalMember(post.JournalID, accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if member.Role == domain.JOURNAL_ROLE_OWNER ||
+			(member.Role == domain.JOURNAL_ROLE_EDITOR && post.AccountID == accountID) {
+			return post, nil
+		}
+	}
+
+	errMsg := fmt.Sprintf("account %s is not allowed to change post %s", accountID, postID)
+	cerr := cerror.NewAndPrintWithTag("GEP00", errors.New(errMsg), global.FRIENDLY_JOURNAL_FORBIDDEN)
+	cerr.Type = cerror.TYPE_UNAUTHORIZED
+	return nil, cerr
+}
+
+// getJournalMember returns the membership of accountID, roles restricts
+// the allowed roles when given
+func (uc PostUsecase) getJournalMember(journalID, accountID string, roles ...string) (*domain.JournalMember, error) {
+	member, err := uc.journalRepo.GetMember(journalID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roles) == 0 {
+		return member, nil
+	}
+
+	for _, role := range roles {
+		if member.Role == role {
+			return member, nil
+		}
+	}
+
+	errMsg := fmt.Sprintf("account %s with role %s is not allowed in journal %s", accountID, member.Role, journalID)
+	cerr := cerror.NewAndPrintWithTag("GJP00", errors.New(errMsg), global.FRIENDLY_JOURNAL_FORBIDDEN)
+	cerr.Type = cerror.TYPE_UNAUTHORIZED
+	return nil, cerr
+}
+
+func (uc PostUsecase) getProfileByUsername(username string) (*domain.Profile, error) {
+	filter := domain.ProfileFilter{Username: username}
+	profile, err := uc.profileRepo.GetProfile(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_PROFILE_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
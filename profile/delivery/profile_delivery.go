@@ -4,28 +4,52 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator"
+	validator "github.com/go-playground/validator/v10"
 	"github.com/pajri/personal-backend/adapter/cerror"
 	"github.com/pajri/personal-backend/domain"
 	"github.com/pajri/personal-backend/global"
 )
 
 type GetProfileResponse struct {
-	Message  string `json:"message"`
-	FullName string `json:"full_name"`
-	Email    string `json:"email"`
+	Message   string `json:"message"`
+	FullName  string `json:"full_name"`
+	Username  string `json:"username"`
+	IsPrivate bool   `json:"is_private"`
+	Email     string `json:"email"`
 }
 
 type UpdateProfileRequest struct {
-	FullName string `json:"full_name" binding:"required"`
+	FullName  string `json:"full_name" binding:"required"`
+	Username  string `json:"username"`
+	IsPrivate *bool  `json:"is_private"`
 }
 
 type UpdateProfileResponse struct {
 	Message []string `json:"message"`
 }
 
+type RestrictionRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type RestrictionResponse struct {
+	Message []string `json:"message"`
+}
+
+type RestrictionListResponse struct {
+	Message         string               `json:"message"`
+	RestrictionList []RestrictionElement `json:"restriction_list"`
+}
+
+type RestrictionElement struct {
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	CreatedAt string `json:"created_at"`
+}
+
 type ProfileHandler struct {
 	useCase domain.IProfileUsecase
 }
@@ -38,6 +62,12 @@ func NewProfileHandler(router *gin.Engine,
 
 	router.GET("/api/profile", handler.GetProfile)
 	router.POST("/api/profile/update", handler.UpdateProfile)
+	router.GET("/api/profile/block", handler.BlockList)
+	router.POST("/api/profile/block", handler.Block)
+	router.POST("/api/profile/unblock", handler.Unblock)
+	router.GET("/api/profile/mute", handler.MuteList)
+	router.POST("/api/profile/mute", handler.Mute)
+	router.POST("/api/profile/unmute", handler.Unmute)
 }
 
 func (ph ProfileHandler) GetProfile(c *gin.Context) {
@@ -61,6 +91,8 @@ func (ph ProfileHandler) GetProfile(c *gin.Context) {
 	}
 
 	response.FullName = profile.FullName
+	response.Username = profile.Username
+	response.IsPrivate = profile.IsPrivate
 	response.Email = profile.Account.Email
 	c.JSON(http.StatusOK, response)
 	return
@@ -108,6 +140,23 @@ func (ph ProfileHandler) UpdateProfile(c *gin.Context) {
 	var profile domain.Profile
 	profile.AccountID = accountID
 	profile.FullName = request.FullName
+	profile.Username = strings.ToLower(request.Username)
+
+	//privacy is only changed when it is sent
+	if request.IsPrivate != nil {
+		profile.IsPrivate = *request.IsPrivate
+		err = ph.useCase.UpdatePrivacy(profile)
+		if err != nil {
+			cerr, ok := err.(cerror.Error)
+			if !ok {
+				cerr = cerror.NewAndPrintWithTag("UPH02", err, global.FRIENDLY_MESSAGE)
+			}
+
+			response.Message = append(response.Message, cerr.FriendlyMessageWithTag())
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
 
 	//update profile
 	err = ph.useCase.UpdateProfile(profile)
@@ -117,9 +166,14 @@ func (ph ProfileHandler) UpdateProfile(c *gin.Context) {
 			cerr = cerror.NewAndPrintWithTag("UPH01", err, global.FRIENDLY_MESSAGE)
 		}
 
+		httpStatus := http.StatusInternalServerError
+		if cerr.Type == cerror.TYPE_BAD_REQUEST {
+			httpStatus = http.StatusBadRequest
+		}
+
 		msg := cerr.FriendlyMessageWithTag()
 		response.Message = append(response.Message, msg)
-		c.JSON(http.StatusInternalServerError, response)
+		c.JSON(httpStatus, response)
 		return
 	}
 
@@ -127,3 +181,89 @@ func (ph ProfileHandler) UpdateProfile(c *gin.Context) {
 	return
 
 }
+
+func (ph ProfileHandler) Block(c *gin.Context) {
+	ph.handleRestriction(c, "BPH00", ph.useCase.Restrict, domain.RESTRICTION_BLOCK)
+}
+
+func (ph ProfileHandler) Unblock(c *gin.Context) {
+	ph.handleRestriction(c, "UBH00", ph.useCase.Unrestrict, domain.RESTRICTION_BLOCK)
+}
+
+func (ph ProfileHandler) Mute(c *gin.Context) {
+	ph.handleRestriction(c, "MPH00", ph.useCase.Restrict, domain.RESTRICTION_MUTE)
+}
+
+func (ph ProfileHandler) Unmute(c *gin.Context) {
+	ph.handleRestriction(c, "UMP00", ph.useCase.Unrestrict, domain.RESTRICTION_MUTE)
+}
+
+func (ph ProfileHandler) BlockList(c *gin.Context) {
+	ph.writeRestrictionList(c, domain.RESTRICTION_BLOCK)
+}
+
+func (ph ProfileHandler) MuteList(c *gin.Context) {
+	ph.writeRestrictionList(c, domain.RESTRICTION_MUTE)
+}
+
+func (ph ProfileHandler) handleRestriction(c *gin.Context, tag string,
+	action func(accountID, username, restrictionType string) error, restrictionType string) {
+	var (
+		request   RestrictionRequest
+		response  RestrictionResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag(tag, err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = action(accountID, strings.ToLower(request.Username), restrictionType)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(ph.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (ph ProfileHandler) writeRestrictionList(c *gin.Context, restrictionType string) {
+	var (
+		response  RestrictionListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	restrictionList, err := ph.useCase.RestrictionList(accountID, restrictionType)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.RestrictionList = []RestrictionElement{}
+	for _, restriction := range restrictionList {
+		response.RestrictionList = append(response.RestrictionList, RestrictionElement{
+			Username:  restriction.Profile.Username,
+			FullName:  restriction.Profile.FullName,
+			CreatedAt: restriction.CreatedAt.Format(global.TIME_ISO8601),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (ph ProfileHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
 	"github.com/pajri/personal-backend/adapter/cerror"
 	"github.com/pajri/personal-backend/domain"
 	"github.com/pajri/personal-backend/global"
@@ -61,13 +62,17 @@ func (pr MySqlProfileRepository) InsertProfile(profile domain.Profile) error {
 }
 
 func (pr MySqlProfileRepository) GetProfile(filter domain.ProfileFilter) (*domain.Profile, error) {
-	query := sq.Select("profile_id, full_name").
+	query := sq.Select("profile_id, full_name, IFNULL(username, ''), is_private, account_id").
 		From("profile")
 
 	if filter.AccountID != "" {
 		query = query.Where(sq.Eq{"account_id": filter.AccountID})
 	}
 
+	if filter.Username != "" {
+		query = query.Where(sq.Eq{"username": filter.Username})
+	}
+
 	sqlString, args, err := query.ToSql()
 	if err != nil {
 		return nil, cerror.NewAndPrintWithTag("GPM00", err, global.FRIENDLY_MESSAGE)
@@ -75,7 +80,7 @@ func (pr MySqlProfileRepository) GetProfile(filter domain.ProfileFilter) (*domai
 
 	row := pr.Db.QueryRow(sqlString, args...)
 	profile := new(domain.Profile)
-	err = row.Scan(&profile.ProfileID, &profile.FullName)
+	err = row.Scan(&profile.ProfileID, &profile.FullName, &profile.Username, &profile.IsPrivate, &profile.AccountID)
 	if err != nil {
 		return nil, cerror.NewAndPrintWithTag("GPM01", err, global.FRIENDLY_MESSAGE)
 	}
@@ -117,3 +122,79 @@ func (pr MySqlProfileRepository) UpdateFullName(profile domain.Profile) error {
 	}
 	return nil
 }
+
+func (pr MySqlProfileRepository) UpdateUsername(profile domain.Profile) error {
+	query := sq.Update("profile").
+		Set("username", profile.Username).
+		Where(sq.Eq{"account_id": profile.AccountID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UUP00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := pr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UUP01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sqlString)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UUP02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sqlString, args...)
+	if err != nil {
+		tx.Rollback()
+		errMySQL, ok := err.(*mysql.MySQLError)
+		if ok && errMySQL.Number == 1062 {
+			cerr := cerror.NewAndPrintWithTag("UUP03", err, global.FRIENDLY_DUPLICATE_USERNAME)
+			cerr.Type = cerror.TYPE_BAD_REQUEST
+			return cerr
+		}
+		return cerror.NewAndPrintWithTag("UUP04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UUP05", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
+
+func (pr MySqlProfileRepository) UpdatePrivacy(profile domain.Profile) error {
+	query := sq.Update("profile").
+		Set("is_private", profile.IsPrivate).
+		Where(sq.Eq{"account_id": profile.AccountID})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPP00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := pr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPP01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sqlString)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UPP02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sqlString, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("UPP03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("UPP04", err, global.FRIENDLY_MESSAGE)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlRestrictionRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlRestrictionRepository(db *sql.DB) domain.IRestrictionRepository {
+	return &MySqlRestrictionRepository{
+		Db: db,
+	}
+}
+
+// InsertRestriction ignores a restriction that already exists
+func (rr MySqlRestrictionRepository) InsertRestriction(restriction domain.Restriction) error {
+	/*start create query*/
+	query := sq.Insert("account_restriction").
+		Options("IGNORE").
+		Columns("account_id", "target_id", "type", "created_at").
+		Values(restriction.AccountID, restriction.TargetID, restriction.Type, time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAR00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := rr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IAR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("IAR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("IAR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+	/*end insert execution*/
+}
+
+func (rr MySqlRestrictionRepository) DeleteRestriction(restriction domain.Restriction) error {
+	query := sq.Delete("account_restriction").
+		Where(sq.Eq{
+			"account_id": restriction.AccountID,
+			"target_id":  restriction.TargetID,
+			"type":       restriction.Type,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DAR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := rr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DAR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DAR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DAR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DAR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (rr MySqlRestrictionRepository) HasRestriction(accountID, targetID, restrictionType string) (bool, error) {
+	query := sq.Select("COUNT(*)").
+		From("account_restriction").
+		Where(sq.Eq{
+			"account_id": accountID,
+			"target_id":  targetID,
+			"type":       restrictionType,
+		})
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("HAR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var count int
+	err = rr.Db.QueryRow(sqlString, args...).Scan(&count)
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("HAR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return count > 0, nil
+}
+
+func (rr MySqlRestrictionRepository) RestrictionList(filter domain.RestrictionFilter) ([]domain.Restriction, error) {
+	query := sq.Select(`account_restriction.account_id, account_restriction.target_id, account_restriction.type,
+		account_restriction.created_at, IFNULL(profile.username, ''), profile.full_name`).
+		From("account_restriction").
+		Join("profile ON profile.account_id = account_restriction.target_id").
+		Where(sq.Eq{"account_restriction.account_id": filter.AccountID}).
+		OrderBy("account_restriction.created_at DESC")
+
+	if filter.Type != "" {
+		query = query.Where(sq.Eq{"account_restriction.type": filter.Type})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("RLR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := rr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("RLR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	restrictionList := []domain.Restriction{}
+	for rows.Next() {
+		var restriction domain.Restriction
+		err = rows.Scan(
+			&restriction.AccountID,
+			&restriction.TargetID,
+			&restriction.Type,
+			&restriction.CreatedAt,
+			&restriction.Profile.Username,
+			&restriction.Profile.FullName,
+		)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("RLR02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		restrictionList = append(restrictionList, restriction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("RLR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return restrictionList, nil
+}
@@ -1,17 +1,34 @@
 package usecase
 
-import "github.com/pajri/personal-backend/domain"
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+var usernameRegex = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
 
 type ProfileUsecase struct {
-	accountRepo domain.IAccountRepository
-	profileRepo domain.IProfileRepository
+	accountRepo     domain.IAccountRepository
+	profileRepo     domain.IProfileRepository
+	restrictionRepo domain.IRestrictionRepository
+	followRepo      domain.IFollowRepository
 }
 
 func NewProfileUsecase(accountRepository domain.IAccountRepository,
-	profileRepository domain.IProfileRepository) domain.IProfileUsecase {
+	profileRepository domain.IProfileRepository,
+	restrictionRepository domain.IRestrictionRepository,
+	followRepository domain.IFollowRepository) domain.IProfileUsecase {
 	return &ProfileUsecase{
-		accountRepo: accountRepository,
-		profileRepo: profileRepository,
+		accountRepo:     accountRepository,
+		profileRepo:     profileRepository,
+		restrictionRepo: restrictionRepository,
+		followRepo:      followRepository,
 	}
 }
 
@@ -35,6 +52,8 @@ func (uc ProfileUsecase) GetProfile(profile domain.Profile) (*domain.Profile, er
 	//populate profile
 	profile.ProfileID = storedProfile.ProfileID
 	profile.FullName = storedProfile.FullName
+	profile.Username = storedProfile.Username
+	profile.IsPrivate = storedProfile.IsPrivate
 	profile.AccountID = storedAccount.AccountID
 	profile.Account = *storedAccount
 
@@ -42,9 +61,101 @@ func (uc ProfileUsecase) GetProfile(profile domain.Profile) (*domain.Profile, er
 }
 
 func (uc ProfileUsecase) UpdateProfile(profile domain.Profile) error {
+	if profile.Username != "" && !usernameRegex.MatchString(profile.Username) {
+		err := fmt.Errorf(global.ERR_INVALID_FORMAT_REGEX, "username", usernameRegex.String())
+		cerr := cerror.NewAndPrintWithTag("UPU00", err, global.FRIENDLY_INVALID_USERNAME)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return cerr
+	}
+
 	err := uc.profileRepo.UpdateFullName(profile)
 	if err != nil {
 		return err
 	}
+
+	if profile.Username != "" {
+		err = uc.profileRepo.UpdateUsername(profile)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc ProfileUsecase) UpdatePrivacy(profile domain.Profile) error {
+	return uc.profileRepo.UpdatePrivacy(profile)
+}
+
+func (uc ProfileUsecase) GetPublicProfile(username string) (*domain.Profile, error) {
+	filter := domain.ProfileFilter{Username: username}
+	profile, err := uc.profileRepo.GetProfile(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_PROFILE_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Restrict blocks or mutes the account of username. Blocking also removes
+// the follow relations between both accounts
+func (uc ProfileUsecase) Restrict(accountID, username, restrictionType string) error {
+	target, err := uc.GetPublicProfile(username)
+	if err != nil {
+		return err
+	}
+
+	if target.AccountID == accountID {
+		cerr := cerror.NewAndPrintWithTag("RSU00", errors.New("account tried to restrict itself"), global.FRIENDLY_RESTRICT_SELF)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return cerr
+	}
+
+	restriction := domain.Restriction{
+		AccountID: accountID,
+		TargetID:  target.AccountID,
+		Type:      restrictionType,
+	}
+	err = uc.restrictionRepo.InsertRestriction(restriction)
+	if err != nil {
+		return err
+	}
+
+	if restrictionType == domain.RESTRICTION_BLOCK {
+		err = uc.followRepo.DeleteFollow(target.AccountID, accountID)
+		if err != nil {
+			return err
+		}
+
+		err = uc.followRepo.DeleteFollow(accountID, target.AccountID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func (uc ProfileUsecase) Unrestrict(accountID, username, restrictionType string) error {
+	target, err := uc.GetPublicProfile(username)
+	if err != nil {
+		return err
+	}
+
+	restriction := domain.Restriction{
+		AccountID: accountID,
+		TargetID:  target.AccountID,
+		Type:      restrictionType,
+	}
+	return uc.restrictionRepo.DeleteRestriction(restriction)
+}
+
+func (uc ProfileUsecase) RestrictionList(accountID, restrictionType string) ([]domain.Restriction, error) {
+	filter := domain.RestrictionFilter{AccountID: accountID, Type: restrictionType}
+	return uc.restrictionRepo.RestrictionList(filter)
+}
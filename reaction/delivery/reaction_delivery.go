@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	validator "github.com/go-playground/validator/v10"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+/* #region type helper */
+type SetReactionRequest struct {
+	Reaction string `json:"reaction" binding:"required,oneof=heart laugh wow sad clap fire"`
+	Reacted  *bool  `json:"reacted" binding:"required"`
+}
+
+type SetReactionResponse struct {
+	Message   []string                 `json:"message"`
+	Reactions []domain.ReactionSummary `json:"reactions"`
+}
+
+/* #endregion */
+
+type ReactionHandler struct {
+	useCase domain.IReactionUsecase
+}
+
+func NewReactionHandler(router *gin.Engine, reactionUsecase domain.IReactionUsecase) {
+	handler := &ReactionHandler{
+		useCase: reactionUsecase,
+	}
+
+	router.POST("/api/post/:post_id/reactions", handler.SetReaction)
+}
+
+func (rh ReactionHandler) SetReaction(c *gin.Context) {
+	var (
+		request   SetReactionRequest
+		response  SetReactionResponse
+		accountID string = c.GetString("account_id")
+		postID    string = c.Param("post_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("SRH00", err, global.FRIENDLY_INVALID_PARAM)
+
+		valError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			response.Message = []string{cerr.FriendlyMessageWithTag()}
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
+		for _, elem := range valError {
+			fieldName := elem.Field()
+			field, _ := reflect.TypeOf(&request).Elem().FieldByName(fieldName)
+			jsonField, _ := field.Tag.Lookup("json")
+
+			switch elem.Tag() {
+			case "required":
+				msg := fmt.Sprintf(global.ERR_REQUIRED_FORMATTER, jsonField)
+				response.Message = append(response.Message, msg)
+				break
+
+			case "oneof":
+				msg := fmt.Sprintf(global.ERR_ONE_OF_FORMATTER, jsonField, elem.Param())
+				response.Message = append(response.Message, msg)
+				break
+			}
+		}
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	reaction := domain.Reaction{
+		PostID:    postID,
+		AccountID: accountID,
+		Reaction:  request.Reaction,
+	}
+
+	summaries, err := rh.useCase.SetReaction(reaction, *request.Reacted)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(rh.errorStatus(cerr), response)
+		return
+	}
+
+	response.Reactions = summaries
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (rh ReactionHandler) errorStatus(cerr cerror.Error) int {
+	if cerr.Type == cerror.TYPE_NOT_FOUND {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
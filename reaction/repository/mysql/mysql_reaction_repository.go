@@ -0,0 +1,159 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type MySqlReactionRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlReactionRepository(db *sql.DB) domain.IReactionRepository {
+	return &MySqlReactionRepository{
+		Db: db,
+	}
+}
+
+// InsertReaction ignores a reaction that already exists, so concurrent or
+// repeated requests leave a single row behind. inserted is false when the
+// row was already there
+func (rr MySqlReactionRepository) InsertReaction(reaction domain.Reaction) (bool, error) {
+	/*start create query*/
+	query := sq.Insert("reaction").
+		Options("IGNORE").
+		Columns("post_id", "account_id", "reaction", "date").
+		Values(reaction.PostID, reaction.AccountID, reaction.Reaction, time.Now())
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("IRR00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := rr.Db.Begin()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("IRR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return false, cerror.NewAndPrintWithTag("IRR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	result, err := tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return false, cerror.NewAndPrintWithTag("IRR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, cerror.NewAndPrintWithTag("IRR05", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, cerror.NewAndPrintWithTag("IRR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return affected > 0, nil
+	/*end insert execution*/
+}
+
+func (rr MySqlReactionRepository) DeleteReaction(reaction domain.Reaction) error {
+	query := sq.Delete("reaction").
+		Where(sq.Eq{
+			"post_id":    reaction.PostID,
+			"account_id": reaction.AccountID,
+			"reaction":   reaction.Reaction,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DRR00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := rr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DRR01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DRR02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DRR03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DRR04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+// ReactionSummaryList counts the reactions of every post in postIDs, keyed by post id.
+// ReactedByMe is set when accountID is one of the reacting accounts
+func (rr MySqlReactionRepository) ReactionSummaryList(postIDs []string, accountID string) (map[string][]domain.ReactionSummary, error) {
+	summaries := make(map[string][]domain.ReactionSummary)
+	if len(postIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := sq.Select("post_id, reaction, COUNT(*)").
+		Column(sq.Expr("MAX(account_id = ?)", accountID)).
+		From("reaction").
+		Where(sq.Eq{"post_id": postIDs}).
+		GroupBy("post_id", "reaction").
+		OrderBy("post_id", "reaction")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("RSL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := rr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("RSL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	for rows.Next() {
+		var (
+			postID  string
+			summary domain.ReactionSummary
+		)
+		err = rows.Scan(&postID, &summary.Reaction, &summary.Count, &summary.ReactedByMe)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("RSL02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		summaries[postID] = append(summaries[postID], summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("RSL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return summaries, nil
+}
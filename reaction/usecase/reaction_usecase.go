@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"database/sql"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+type ReactionUsecase struct {
+	reactionRepo domain.IReactionRepository
+	postRepo     domain.IPostRepository
+	notifier     domain.INotifier
+}
+
+func NewReactionUsecase(reactionRepository domain.IReactionRepository,
+	postRepository domain.IPostRepository,
+	notifier domain.INotifier) domain.IReactionUsecase {
+	return &ReactionUsecase{
+		reactionRepo: reactionRepository,
+		postRepo:     postRepository,
+		notifier:     notifier,
+	}
+}
+
+// SetReaction adds the reaction when reacted is true and removes it otherwise,
+// repeating the same request does not change the result
+func (uc ReactionUsecase) SetReaction(reaction domain.Reaction, reacted bool) ([]domain.ReactionSummary, error) {
+	filter := domain.PostFilter{PostID: reaction.PostID, ViewerID: reaction.AccountID}
+	post, err := uc.postRepo.GetPost(filter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	var inserted bool
+	if reacted {
+		inserted, err = uc.reactionRepo.InsertReaction(reaction)
+	} else {
+		err = uc.reactionRepo.DeleteReaction(reaction)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//repeating the request must not notify the owner again
+	if inserted {
+		uc.notifier.Notify(domain.Notification{
+			AccountID: post.AccountID,
+			ActorID:   reaction.AccountID,
+			Type:      domain.NOTIFICATION_REACTION,
+			PostID:    reaction.PostID,
+		})
+	}
+
+	summaries, err := uc.reactionRepo.ReactionSummaryList([]string{reaction.PostID}, reaction.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if summaries[reaction.PostID] == nil {
+		return []domain.ReactionSummary{}, nil
+	}
+
+	return summaries[reaction.PostID], nil
+}
@@ -0,0 +1,267 @@
+package delivery
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+/* #region type helper */
+type CreateShareLinkRequest struct {
+	PostID    string     `json:"post_id" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"password"`
+}
+
+type CreateShareLinkResponse struct {
+	Message   []string          `json:"message"`
+	ShareLink *ShareLinkElement `json:"share_link,omitempty"`
+}
+
+type ShareLinkListRequest struct {
+	PostID string `form:"post_id"`
+}
+
+type ShareLinkListResponse struct {
+	Message       string             `json:"message"`
+	ShareLinkList []ShareLinkElement `json:"share_link_list"`
+}
+
+type ShareLinkElement struct {
+	ShareLinkID string `json:"share_link_id"`
+	PostID      string `json:"post_id"`
+	ShareURL    string `json:"share_url,omitempty"`
+	HasPassword bool   `json:"has_password"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type RevokeShareLinkRequest struct {
+	ShareLinkID string `json:"share_link_id" binding:"required"`
+}
+
+type RevokeShareLinkResponse struct {
+	Message []string `json:"message"`
+}
+
+type SharedPostRequest struct {
+	Password string `json:"password"`
+}
+
+type SharedPostResponse struct {
+	Message string             `json:"message"`
+	Post    *SharedPostElement `json:"post,omitempty"`
+}
+
+type SharedPostElement struct {
+	Content     string   `json:"content"`
+	ContentHTML string   `json:"content_html"`
+	ImageURL    string   `json:"image_url"`
+	MoodEmoji   string   `json:"mood_emoji,omitempty"`
+	PlaceName   string   `json:"place_name,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	Weather     string   `json:"weather,omitempty"`
+	Date        string   `json:"date"`
+	HiddenDate  string   `json:"hidden_date"`
+}
+
+/* #endregion */
+
+type ShareLinkHandler struct {
+	useCase domain.IShareLinkUsecase
+}
+
+func NewShareLinkHandler(router *gin.Engine, shareLinkUsecase domain.IShareLinkUsecase) {
+	handler := &ShareLinkHandler{
+		useCase: shareLinkUsecase,
+	}
+
+	router.POST("/api/share", handler.CreateShareLink)
+	router.GET("/api/share", handler.ShareLinkList)
+	router.POST("/api/share/revoke", handler.RevokeShareLink)
+
+	//public routes, see excludedFromAuth
+	router.GET("/api/shared/:token", handler.SharedPost)
+	router.POST("/api/shared/:token", handler.SharedPost)
+}
+
+func (sh ShareLinkHandler) CreateShareLink(c *gin.Context) {
+	var (
+		request   CreateShareLinkRequest
+		response  CreateShareLinkResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("CSH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var shareLink domain.ShareLink
+	shareLink.PostID = request.PostID
+	shareLink.AccountID = accountID
+	shareLink.ExpiresAt = request.ExpiresAt
+
+	storedShareLink, err := sh.useCase.CreateShareLink(shareLink, request.Password)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(sh.errorStatus(cerr), response)
+		return
+	}
+
+	element := sh.createShareLinkElement(*storedShareLink)
+	element.ShareURL = fmt.Sprintf("%s/share/%s", config.Config.FEHost, storedShareLink.Token)
+	response.ShareLink = &element
+	c.JSON(http.StatusCreated, response)
+	return
+}
+
+func (sh ShareLinkHandler) ShareLinkList(c *gin.Context) {
+	var (
+		request   ShareLinkListRequest
+		response  ShareLinkListResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("SLH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	shareLinkList, err := sh.useCase.ShareLinkList(accountID, request.PostID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.ShareLinkList = []ShareLinkElement{}
+	for _, shareLink := range shareLinkList {
+		response.ShareLinkList = append(response.ShareLinkList, sh.createShareLinkElement(shareLink))
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (sh ShareLinkHandler) RevokeShareLink(c *gin.Context) {
+	var (
+		request   RevokeShareLinkRequest
+		response  RevokeShareLinkResponse
+		accountID string = c.GetString("account_id")
+	)
+
+	err := c.ShouldBind(&request)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("RSH00", err, global.FRIENDLY_INVALID_PARAM)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = sh.useCase.RevokeShareLink(request.ShareLinkID, accountID)
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = []string{cerr.FriendlyMessageWithTag()}
+		c.JSON(sh.errorStatus(cerr), response)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (sh ShareLinkHandler) SharedPost(c *gin.Context) {
+	var (
+		request  SharedPostRequest
+		response SharedPostResponse
+		token    string = c.Param("token")
+	)
+
+	//password is sent in the body so it doesn't end up in access logs
+	if c.Request.Method == http.MethodPost {
+		err := c.ShouldBind(&request)
+		if err != nil {
+			cerr := cerror.NewAndPrintWithTag("SPH00", err, global.FRIENDLY_INVALID_PARAM)
+			response.Message = cerr.FriendlyMessageWithTag()
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	clientIP := util.ClientIP(c.Request, config.Config.TrustedProxies)
+	post, err := sh.useCase.GetSharedPost(token, request.Password, clientIP)
+	if lerr, ok := err.(domain.ShareLinkLockedError); ok {
+		retryAfter := int(math.Ceil(lerr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		response.Message = global.FRIENDLY_SHARE_LINK_LOCKED
+		c.JSON(http.StatusTooManyRequests, response)
+		return
+	}
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		c.JSON(sh.errorStatus(cerr), response)
+		return
+	}
+
+	response.Post = &SharedPostElement{
+		Content:     post.Content,
+		ContentHTML: post.ContentHTML,
+		ImageURL:    post.ImageURL,
+		MoodEmoji:   domain.MoodEmoji[post.Mood],
+		PlaceName:   post.PlaceName,
+		Latitude:    post.Latitude,
+		Longitude:   post.Longitude,
+		Weather:     post.Weather,
+		Date:        post.Date.Format(global.TIME_FORMAT),
+		HiddenDate:  post.Date.Format(global.TIME_ISO8601),
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+func (sh ShareLinkHandler) createShareLinkElement(shareLink domain.ShareLink) ShareLinkElement {
+	var element ShareLinkElement
+	element.ShareLinkID = shareLink.ShareLinkID
+	element.PostID = shareLink.PostID
+	element.HasPassword = shareLink.PasswordHash != ""
+	element.CreatedAt = shareLink.CreatedAt.Format(global.TIME_ISO8601)
+	if shareLink.ExpiresAt != nil {
+		element.ExpiresAt = shareLink.ExpiresAt.Format(global.TIME_ISO8601)
+	}
+
+	return element
+}
+
+func (sh ShareLinkHandler) errorStatus(cerr cerror.Error) int {
+	switch cerr.Type {
+	case cerror.TYPE_NOT_FOUND:
+		return http.StatusNotFound
+	case cerror.TYPE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case cerror.TYPE_UNAUTHORIZED:
+		return http.StatusUnauthorized
+	case cerror.TYPE_EXPIRED:
+		return http.StatusGone
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,202 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+)
+
+type MySqlShareLinkRepository struct {
+	Db *sql.DB
+}
+
+func NewMySqlShareLinkRepository(db *sql.DB) domain.IShareLinkRepository {
+	return &MySqlShareLinkRepository{
+		Db: db,
+	}
+}
+
+func (sr MySqlShareLinkRepository) InsertShareLink(shareLink domain.ShareLink) (*domain.ShareLink, error) {
+	if shareLink.ShareLinkID == "" {
+		shareLink.ShareLinkID = util.GenerateUUID()
+	}
+	shareLink.CreatedAt = time.Now()
+
+	/*start create query*/
+	query := sq.Insert("share_link").
+		Columns("share_link_id", "post_id", "account_id", "token_hash", "password_hash", "expires_at", "created_at").
+		Values(shareLink.ShareLinkID, shareLink.PostID, shareLink.AccountID, shareLink.TokenHash,
+			shareLink.PasswordHash, shareLink.ExpiresAt, shareLink.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ISL00", err, global.FRIENDLY_MESSAGE)
+	}
+	/*end create query*/
+
+	/*start insert execution*/
+	tx, err := sr.Db.Begin()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ISL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ISL02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, cerror.NewAndPrintWithTag("ISL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("ISL04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return &shareLink, nil
+	/*end insert execution*/
+}
+
+func (sr MySqlShareLinkRepository) GetShareLink(filter domain.ShareLinkFilter) (*domain.ShareLink, error) {
+	query := sr.selectQuery(filter)
+
+	sqlString, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("GSL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	row := sr.Db.QueryRow(sqlString, args...)
+
+	shareLink := new(domain.ShareLink)
+	err = sr.scanShareLink(row, shareLink)
+	if err != nil {
+		cerr := cerror.NewAndPrintWithTag("GSL01", err, global.FRIENDLY_SHARE_LINK_NOT_FOUND)
+		if err == sql.ErrNoRows {
+			cerr.Type = cerror.TYPE_NOT_FOUND
+		} else {
+			cerr.FriendlyMessage = global.FRIENDLY_MESSAGE
+		}
+		return nil, cerr
+	}
+
+	return shareLink, nil
+}
+
+func (sr MySqlShareLinkRepository) ShareLinkList(filter domain.ShareLinkFilter) ([]domain.ShareLink, error) {
+	query := sr.selectQuery(filter).OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("SLL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	rows, err := sr.Db.Query(sql, args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("SLL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	var shareLinkList []domain.ShareLink
+	for rows.Next() {
+		var shareLink domain.ShareLink
+		err = sr.scanShareLink(rows, &shareLink)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("SLL02", err, global.FRIENDLY_MESSAGE)
+		}
+
+		shareLinkList = append(shareLinkList, shareLink)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, cerror.NewAndPrintWithTag("SLL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return shareLinkList, nil
+}
+
+func (sr MySqlShareLinkRepository) DeleteShareLink(shareLinkID, accountID string) error {
+	query := sq.Delete("share_link").
+		Where(sq.Eq{
+			"share_link_id": shareLinkID,
+			"account_id":    accountID,
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DSL00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	tx, err := sr.Db.Begin()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DSL01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DSL02", err, global.FRIENDLY_MESSAGE)
+	}
+	defer stmt.Close()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return cerror.NewAndPrintWithTag("DSL03", err, global.FRIENDLY_MESSAGE)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return cerror.NewAndPrintWithTag("DSL04", err, global.FRIENDLY_MESSAGE)
+	}
+
+	return nil
+}
+
+func (sr MySqlShareLinkRepository) selectQuery(filter domain.ShareLinkFilter) sq.SelectBuilder {
+	query := sq.Select(`share_link_id, post_id, account_id, token_hash,
+		IFNULL(password_hash, ''), expires_at, created_at`).
+		From("share_link")
+
+	if filter.ShareLinkID != "" {
+		query = query.Where(sq.Eq{"share_link_id": filter.ShareLinkID})
+	}
+
+	if filter.AccountID != "" {
+		query = query.Where(sq.Eq{"account_id": filter.AccountID})
+	}
+
+	if filter.PostID != "" {
+		query = query.Where(sq.Eq{"post_id": filter.PostID})
+	}
+
+	if filter.TokenHash != "" {
+		query = query.Where(sq.Eq{"token_hash": filter.TokenHash})
+	}
+
+	return query
+}
+
+func (sr MySqlShareLinkRepository) scanShareLink(row interface{ Scan(...interface{}) error }, shareLink *domain.ShareLink) error {
+	return row.Scan(
+		&shareLink.ShareLinkID,
+		&shareLink.PostID,
+		&shareLink.AccountID,
+		&shareLink.TokenHash,
+		&shareLink.PasswordHash,
+		&shareLink.ExpiresAt,
+		&shareLink.CreatedAt,
+	)
+}
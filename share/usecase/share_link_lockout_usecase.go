@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/helper"
+)
+
+const (
+	SHARE_LINK_FAILURE_PREFIX = "share_link_failure:"
+	SHARE_LINK_LOCK_PREFIX    = "share_link_lock:"
+	SHARE_LINK_FAILURE_WINDOW = 24 * time.Hour
+
+	//wrong passwords allowed before the first lock, an ip address may
+	//open links of many owners
+	SHARE_LINK_FREE_ATTEMPTS    = 5
+	SHARE_LINK_IP_FREE_ATTEMPTS = 20
+
+	SHARE_LINK_LOCK_MIN = time.Minute
+	SHARE_LINK_LOCK_MAX = time.Hour
+)
+
+// shareLinkThrottle counts wrong passwords of one share link or one ip
+// address, the same way failed logins are counted
+type shareLinkThrottle struct {
+	key          string
+	freeAttempts int64
+}
+
+func (uc ShareLinkUsecase) shareLinkThrottles(tokenHash, clientIP string) []shareLinkThrottle {
+	throttles := []shareLinkThrottle{{
+		key:          "token:" + tokenHash,
+		freeAttempts: SHARE_LINK_FREE_ATTEMPTS,
+	}}
+
+	if clientIP != "" {
+		throttles = append(throttles, shareLinkThrottle{
+			key:          "ip:" + clientIP,
+			freeAttempts: SHARE_LINK_IP_FREE_ATTEMPTS,
+		})
+	}
+
+	return throttles
+}
+
+// checkShareLinkLock returns ShareLinkLockedError while the share link or
+// the ip address is locked
+func (uc ShareLinkUsecase) checkShareLinkLock(throttles []shareLinkThrottle) error {
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		value, _ := helper.RedisHelper.Get(SHARE_LINK_LOCK_PREFIX + throttle.key)
+		unlockAt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		remaining := time.Until(time.Unix(unlockAt, 0))
+		if remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	if retryAfter > 0 {
+		return domain.ShareLinkLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// recordShareLinkFailure returns ShareLinkLockedError once the free
+// attempts are used, every further failure doubles the lock, or
+// passwordErr before that
+func (uc ShareLinkUsecase) recordShareLinkFailure(throttles []shareLinkThrottle, passwordErr error) error {
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		failures, err := helper.RedisHelper.Increment(SHARE_LINK_FAILURE_PREFIX+throttle.key, now.Add(SHARE_LINK_FAILURE_WINDOW).Unix())
+		if err != nil {
+			return err
+		}
+
+		if failures < throttle.freeAttempts {
+			continue
+		}
+
+		lock := uc.shareLinkLockDuration(failures - throttle.freeAttempts)
+		unlockAt := now.Add(lock).Unix()
+		err = helper.RedisHelper.Set(SHARE_LINK_LOCK_PREFIX+throttle.key, unlockAt, unlockAt)
+		if err != nil {
+			return err
+		}
+
+		if lock > retryAfter {
+			retryAfter = lock
+		}
+	}
+
+	if retryAfter > 0 {
+		return domain.ShareLinkLockedError{RetryAfter: retryAfter}
+	}
+
+	return passwordErr
+}
+
+// resetShareLinkFailures forgets the failures of the share link once the
+// password is right, the ip address keeps its count
+func (uc ShareLinkUsecase) resetShareLinkFailures(tokenHash string) {
+	throttle := uc.shareLinkThrottles(tokenHash, "")[0]
+	_ = helper.RedisHelper.Delete(SHARE_LINK_FAILURE_PREFIX + throttle.key)
+}
+
+func (uc ShareLinkUsecase) shareLinkLockDuration(extraFailures int64) time.Duration {
+	if extraFailures >= 32 {
+		return SHARE_LINK_LOCK_MAX
+	}
+
+	lock := SHARE_LINK_LOCK_MIN * time.Duration(math.Pow(2, float64(extraFailures)))
+	if lock > SHARE_LINK_LOCK_MAX {
+		return SHARE_LINK_LOCK_MAX
+	}
+
+	return lock
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const SHARE_TOKEN_BYTES = 32
+
+type ShareLinkUsecase struct {
+	shareLinkRepo domain.IShareLinkRepository
+	postRepo      domain.IPostRepository
+	notifier      domain.INotifier
+}
+
+func NewShareLinkUsecase(shareLinkRepository domain.IShareLinkRepository,
+	postRepository domain.IPostRepository,
+	notifier domain.INotifier) domain.IShareLinkUsecase {
+	return &ShareLinkUsecase{
+		shareLinkRepo: shareLinkRepository,
+		postRepo:      postRepository,
+		notifier:      notifier,
+	}
+}
+
+func (uc ShareLinkUsecase) CreateShareLink(shareLink domain.ShareLink, password string) (*domain.ShareLink, error) {
+	//only the owner can share a post
+	postFilter := domain.PostFilter{PostID: shareLink.PostID, AccountID: shareLink.AccountID}
+	_, err := uc.postRepo.GetPost(postFilter)
+	if err != nil {
+		cerr, ok := err.(cerror.Error)
+		if ok && cerr.Err == sql.ErrNoRows {
+			cerr.FriendlyMessage = global.FRIENDLY_POST_NOT_FOUND
+			cerr.Type = cerror.TYPE_NOT_FOUND
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	if shareLink.ExpiresAt != nil && shareLink.ExpiresAt.Before(time.Now()) {
+		cerr := cerror.NewAndPrintWithTag("CSL00", errors.New("share link expiry is in the past"), global.FRIENDLY_SHARE_LINK_INVALID_EXPIRY)
+		cerr.Type = cerror.TYPE_BAD_REQUEST
+		return nil, cerr
+	}
+
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, cerror.NewAndPrintWithTag("CSL01", err, global.FRIENDLY_MESSAGE)
+		}
+		shareLink.PasswordHash = string(hash)
+	}
+
+	//only the hash is stored, the token is shown to the owner once
+	shareLink.Token, err = util.GenerateToken(SHARE_TOKEN_BYTES)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("CSL02", err, global.FRIENDLY_MESSAGE)
+	}
+	shareLink.TokenHash = util.HashToken(shareLink.Token)
+
+	storedShareLink, err := uc.shareLinkRepo.InsertShareLink(shareLink)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedShareLink, nil
+}
+
+func (uc ShareLinkUsecase) ShareLinkList(accountID, postID string) ([]domain.ShareLink, error) {
+	filter := domain.ShareLinkFilter{AccountID: accountID, PostID: postID}
+	return uc.shareLinkRepo.ShareLinkList(filter)
+}
+
+func (uc ShareLinkUsecase) RevokeShareLink(shareLinkID, accountID string) error {
+	filter := domain.ShareLinkFilter{ShareLinkID: shareLinkID, AccountID: accountID}
+	_, err := uc.shareLinkRepo.GetShareLink(filter)
+	if err != nil {
+		return err
+	}
+
+	return uc.shareLinkRepo.DeleteShareLink(shareLinkID, accountID)
+}
+
+func (uc ShareLinkUsecase) GetSharedPost(token, password, clientIP string) (*domain.Post, error) {
+	tokenHash := util.HashToken(token)
+	filter := domain.ShareLinkFilter{TokenHash: tokenHash}
+	shareLink, err := uc.shareLinkRepo.GetShareLink(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if shareLink.ExpiresAt != nil && time.Now().After(*shareLink.ExpiresAt) {
+		errMsg := fmt.Sprintf("share link %s is expired", shareLink.ShareLinkID)
+		cerr := cerror.NewAndPrintWithTag("GSP00", errors.New(errMsg), global.FRIENDLY_SHARE_LINK_EXPIRED)
+		cerr.Type = cerror.TYPE_EXPIRED
+		return nil, cerr
+	}
+
+	if shareLink.PasswordHash != "" {
+		//checked before bcrypt so a locked link costs no hashing
+		throttles := uc.shareLinkThrottles(tokenHash, clientIP)
+		err = uc.checkShareLinkLock(throttles)
+		if err != nil {
+			return nil, err
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(shareLink.PasswordHash), []byte(password))
+		if err != nil {
+			cerr := cerror.NewAndPrintWithTag("GSP01", err, global.FRIENDLY_SHARE_LINK_PASSWORD)
+			cerr.Type = cerror.TYPE_UNAUTHORIZED
+			return nil, uc.recordShareLinkFailure(throttles, cerr)
+		}
+		uc.resetShareLinkFailures(tokenHash)
+	}
+
+	postFilter := domain.PostFilter{PostID: shareLink.PostID, AccountID: shareLink.AccountID}
+	post, err := uc.postRepo.GetPost(postFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.notifier.Notify(domain.Notification{
+		AccountID: shareLink.AccountID,
+		Type:      domain.NOTIFICATION_SHARE_LINK_VIEW,
+		PostID:    shareLink.PostID,
+	})
+
+	return post, nil
+}
@@ -0,0 +1,117 @@
+package delivery
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+)
+
+// HEARTBEAT_INTERVAL keeps idle connections open behind proxies
+const HEARTBEAT_INTERVAL = 25 * time.Second
+
+/* #region type helper */
+type StreamResponse struct {
+	Message string `json:"message"`
+}
+
+type StreamTicketResponse struct {
+	Message string `json:"message"`
+	Ticket  string `json:"ticket,omitempty"`
+}
+
+/* #endregion */
+
+type StreamHandler struct {
+	useCase domain.IStreamUsecase
+}
+
+func NewStreamHandler(router *gin.Engine, streamUsecase domain.IStreamUsecase) {
+	handler := &StreamHandler{
+		useCase: streamUsecase,
+	}
+
+	router.POST("/api/stream/ticket", handler.CreateTicket)
+
+	//public route, see excludedFromAuth, the ticket stands in for the token
+	router.GET("/api/stream", handler.Stream)
+}
+
+// CreateTicket is called with the access token in the header, browsers can
+// not set headers on an EventSource so it connects with the ticket instead
+func (sh StreamHandler) CreateTicket(c *gin.Context) {
+	var response StreamTicketResponse
+
+	ticket := domain.StreamTicket{
+		AccountID:  c.GetString("account_id"),
+		AccessUUID: c.GetString("access_uuid"),
+		ExpiresAt:  c.GetInt64("access_exp"),
+	}
+
+	token, err := sh.useCase.CreateTicket(ticket)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Ticket = token
+	c.JSON(http.StatusOK, response)
+}
+
+// Stream pushes the events of the account as server-sent events until the
+// client disconnects or its access token stops being valid
+func (sh StreamHandler) Stream(c *gin.Context) {
+	var response StreamResponse
+
+	ticket, err := sh.useCase.UseTicket(c.Query("ticket"))
+	if err != nil {
+		cerr := err.(cerror.Error)
+		response.Message = cerr.FriendlyMessageWithTag()
+		if cerr.Type == cerror.TYPE_UNAUTHORIZED {
+			c.JSON(http.StatusUnauthorized, response)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	events, cancel, err := sh.useCase.Subscribe(ticket.AccountID)
+	if err != nil {
+		response.Message = err.(cerror.Error).FriendlyMessageWithTag()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer cancel()
+
+	heartbeat := time.NewTicker(HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			//the client asks for a new ticket after a refresh
+			if !sh.useCase.IsTicketActive(*ticket) {
+				c.SSEvent("token_expired", time.Now().Unix())
+				return false
+			}
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+	"github.com/pajri/personal-backend/helper"
+	"github.com/pajri/personal-backend/util"
+)
+
+const (
+	STREAM_CHANNEL_PREFIX = "stream:"
+	STREAM_BUFFER_SIZE    = 16
+
+	STREAM_TICKET_PREFIX = "stream_ticket:"
+	STREAM_TICKET_EXPIRY = 30 * time.Second
+)
+
+// StreamUsecase fans events out through redis pub/sub so every backend
+// instance can deliver them to its own connected clients
+type StreamUsecase struct {
+	redisHelper helper.IRedis
+}
+
+func NewStreamUsecase(redisHelper helper.IRedis) domain.IStreamUsecase {
+	return &StreamUsecase{
+		redisHelper: redisHelper,
+	}
+}
+
+func (uc StreamUsecase) Publish(event domain.Event, accountIDs ...string) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		_ = cerror.NewAndPrintWithTag("PSU00", err, global.FRIENDLY_MESSAGE)
+		return
+	}
+
+	published := make(map[string]bool)
+	for _, accountID := range accountIDs {
+		if accountID == "" || published[accountID] {
+			continue
+		}
+		published[accountID] = true
+
+		//the helper already prints the error
+		_ = uc.redisHelper.Publish(STREAM_CHANNEL_PREFIX+accountID, message)
+	}
+}
+
+func (uc StreamUsecase) Subscribe(accountID string) (<-chan domain.Event, func(), error) {
+	subscription, err := uc.redisHelper.Subscribe(STREAM_CHANNEL_PREFIX + accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		events = make(chan domain.Event, STREAM_BUFFER_SIZE)
+		done   = make(chan struct{})
+		once   sync.Once
+	)
+
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			subscription.Close()
+		})
+	}
+
+	go func() {
+		defer close(events)
+		for {
+			message, err := subscription.Receive()
+			if err != nil {
+				return
+			}
+
+			var event domain.Event
+			err = json.Unmarshal(message, &event)
+			if err != nil {
+				_ = cerror.NewAndPrintWithTag("SSU00", err, global.FRIENDLY_MESSAGE)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return events, cancel, nil
+}
+
+// CreateTicket stores only the hash of the ticket, the ticket ends up in
+// access logs as part of the url
+func (uc StreamUsecase) CreateTicket(ticket domain.StreamTicket) (string, error) {
+	value, err := json.Marshal(ticket)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("CST00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	token, err := util.GenerateToken(32)
+	if err != nil {
+		return "", cerror.NewAndPrintWithTag("CST01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	exp := time.Now().Add(STREAM_TICKET_EXPIRY).Unix()
+	err = uc.redisHelper.Set(STREAM_TICKET_PREFIX+util.HashToken(token), value, exp)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// UseTicket pops the ticket so a logged url can not be replayed
+func (uc StreamUsecase) UseTicket(ticket string) (*domain.StreamTicket, error) {
+	value, err := uc.redisHelper.Pop(STREAM_TICKET_PREFIX + util.HashToken(ticket))
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		cerr := cerror.NewAndPrintWithTag("UST00", errors.New("stream ticket is not found or expired"), global.FRIENDLY_STREAM_TICKET_INVALID)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	var streamTicket domain.StreamTicket
+	err = json.Unmarshal([]byte(value), &streamTicket)
+	if err != nil {
+		return nil, cerror.NewAndPrintWithTag("UST01", err, global.FRIENDLY_MESSAGE)
+	}
+
+	if !uc.IsTicketActive(streamTicket) {
+		cerr := cerror.NewAndPrintWithTag("UST02", errors.New("access token of the stream ticket is no longer valid"), global.FRIENDLY_STREAM_TICKET_INVALID)
+		cerr.Type = cerror.TYPE_UNAUTHORIZED
+		return nil, cerr
+	}
+
+	return &streamTicket, nil
+}
+
+// IsTicketActive checks the same redis key as the auth middleware, signing
+// out and revoking a session delete it
+func (uc StreamUsecase) IsTicketActive(ticket domain.StreamTicket) bool {
+	if time.Now().Unix() >= ticket.ExpiresAt {
+		return false
+	}
+
+	accessToken, _ := uc.redisHelper.Get(ticket.AccessUUID)
+	return accessToken != ""
+}
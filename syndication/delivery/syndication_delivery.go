@@ -0,0 +1,421 @@
+package delivery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pajri/personal-backend/adapter/cerror"
+	"github.com/pajri/personal-backend/config"
+	"github.com/pajri/personal-backend/domain"
+	"github.com/pajri/personal-backend/global"
+)
+
+const (
+	FEED_LIMIT       = 50
+	FEED_TITLE_CHARS = 80
+
+	CONTENT_TYPE_ATOM = "application/atom+xml; charset=utf-8"
+	CONTENT_TYPE_RSS  = "application/rss+xml; charset=utf-8"
+	CONTENT_TYPE_JSON = "application/feed+json; charset=utf-8"
+)
+
+/* #region type helper */
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []AtomLink  `xml:"link"`
+	Author  AtomAuthor  `xml:"author"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Links     []AtomLink  `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Content   AtomContent `xml:"content"`
+}
+
+type AtomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Enclosure   *RSSEnclosure `xml:"enclosure,omitempty"`
+}
+
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Authors     []JSONFeedAuthor `json:"authors"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+type FeedErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// feedData is the format independent content of a feed
+type feedData struct {
+	profile  domain.Profile
+	postList []domain.Post
+	updated  time.Time
+	homeURL  string
+	feedURL  string
+}
+
+// enclosure describes the image attached to a post
+type enclosure struct {
+	url      string
+	mimeType string
+	length   int64
+}
+
+/* #endregion */
+
+type SyndicationHandler struct {
+	postUsecase    domain.IPostUsecase
+	profileUsecase domain.IProfileUsecase
+}
+
+func NewSyndicationHandler(router *gin.Engine, postUsecase domain.IPostUsecase,
+	profileUsecase domain.IProfileUsecase) {
+	handler := &SyndicationHandler{
+		postUsecase:    postUsecase,
+		profileUsecase: profileUsecase,
+	}
+
+	//public routes, see excludedFromAuth
+	router.GET("/u/:username/feed.atom", handler.AtomFeed)
+	router.GET("/u/:username/feed.rss", handler.RSSFeed)
+	router.GET("/u/:username/feed.json", handler.JSONFeed)
+}
+
+func (sh SyndicationHandler) AtomFeed(c *gin.Context) {
+	data, ok := sh.loadFeed(c, "feed.atom")
+	if !ok {
+		return
+	}
+
+	feed := AtomFeed{
+		Title:   data.profile.FullName,
+		ID:      data.homeURL,
+		Updated: data.updated.Format(time.RFC3339),
+		Links: []AtomLink{
+			{Rel: "self", Type: CONTENT_TYPE_ATOM, Href: data.feedURL},
+			{Rel: "alternate", Type: "text/html", Href: data.homeURL},
+		},
+		Author: AtomAuthor{Name: data.profile.FullName},
+	}
+
+	for _, post := range data.postList {
+		entry := AtomEntry{
+			Title:     sh.postTitle(post),
+			ID:        "urn:uuid:" + post.PostID,
+			Links:     []AtomLink{{Rel: "alternate", Type: "text/html", Href: sh.postURL(data.profile, post)}},
+			Published: post.Date.Format(time.RFC3339),
+			Updated:   post.LastUpdated.Format(time.RFC3339),
+			Content:   AtomContent{Type: "html", Body: post.ContentHTML},
+		}
+
+		if image := sh.postEnclosure(post); image != nil {
+			entry.Links = append(entry.Links, AtomLink{
+				Rel:    "enclosure",
+				Type:   image.mimeType,
+				Length: image.length,
+				Href:   image.url,
+			})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	sh.writeXML(c, CONTENT_TYPE_ATOM, feed)
+}
+
+func (sh SyndicationHandler) RSSFeed(c *gin.Context) {
+	data, ok := sh.loadFeed(c, "feed.rss")
+	if !ok {
+		return
+	}
+
+	feed := RSSFeed{
+		Version: "2.0",
+		Channel: RSSChannel{
+			Title:         data.profile.FullName,
+			Link:          data.homeURL,
+			Description:   fmt.Sprintf("Public moments of %s", data.profile.FullName),
+			LastBuildDate: data.updated.Format(time.RFC1123Z),
+		},
+	}
+
+	for _, post := range data.postList {
+		postURL := sh.postURL(data.profile, post)
+		item := RSSItem{
+			Title:       sh.postTitle(post),
+			Link:        postURL,
+			GUID:        RSSGUID{IsPermaLink: true, Value: postURL},
+			PubDate:     post.Date.Format(time.RFC1123Z),
+			Description: post.ContentHTML,
+		}
+
+		if image := sh.postEnclosure(post); image != nil {
+			item.Enclosure = &RSSEnclosure{
+				URL:    image.url,
+				Length: image.length,
+				Type:   image.mimeType,
+			}
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	sh.writeXML(c, CONTENT_TYPE_RSS, feed)
+}
+
+func (sh SyndicationHandler) JSONFeed(c *gin.Context) {
+	data, ok := sh.loadFeed(c, "feed.json")
+	if !ok {
+		return
+	}
+
+	feed := JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       data.profile.FullName,
+		HomePageURL: data.homeURL,
+		FeedURL:     data.feedURL,
+		Authors:     []JSONFeedAuthor{{Name: data.profile.FullName}},
+		Items:       []JSONFeedItem{},
+	}
+
+	for _, post := range data.postList {
+		item := JSONFeedItem{
+			ID:            post.PostID,
+			URL:           sh.postURL(data.profile, post),
+			Title:         sh.postTitle(post),
+			ContentHTML:   post.ContentHTML,
+			ContentText:   post.Content,
+			DatePublished: post.Date.Format(time.RFC3339),
+			DateModified:  post.LastUpdated.Format(time.RFC3339),
+		}
+
+		if image := sh.postEnclosure(post); image != nil {
+			item.Image = image.url
+			item.Attachments = []JSONFeedAttachment{{
+				URL:         image.url,
+				MimeType:    image.mimeType,
+				SizeInBytes: image.length,
+			}}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	c.Header("Content-Type", CONTENT_TYPE_JSON)
+	c.JSON(http.StatusOK, feed)
+}
+
+// loadFeed fetches the profile and public posts, it also answers conditional
+// requests so the caller only has to render when ok is true
+func (sh SyndicationHandler) loadFeed(c *gin.Context, feedName string) (*feedData, bool) {
+	username := c.Param("username")
+
+	profile, err := sh.profileUsecase.GetPublicProfile(username)
+	if err != nil {
+		sh.writeError(c, err)
+		return nil, false
+	}
+
+	var zeroTime time.Time
+	postList, err := sh.postUsecase.PublicPostListing(username, FEED_LIMIT, zeroTime)
+	if err != nil {
+		sh.writeError(c, err)
+		return nil, false
+	}
+
+	data := &feedData{
+		profile:  *profile,
+		postList: postList,
+		homeURL:  fmt.Sprintf("%s/u/%s", config.Config.FEHost, profile.Username),
+		feedURL:  fmt.Sprintf("%s/u/%s/%s", config.Config.Host, profile.Username, feedName),
+	}
+
+	//feed is updated whenever one of its posts is
+	hash := sha256.New()
+	hash.Write([]byte(feedName + profile.FullName))
+	for _, post := range postList {
+		if post.LastUpdated.After(data.updated) {
+			data.updated = post.LastUpdated
+		}
+		hash.Write([]byte(post.PostID + post.LastUpdated.UTC().Format(time.RFC3339Nano)))
+	}
+	if data.updated.IsZero() {
+		data.updated = time.Now()
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", data.updated.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=300")
+
+	if match := c.GetHeader("If-None-Match"); match != "" && sh.etagMatch(match, etag) {
+		c.Status(http.StatusNotModified)
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (sh SyndicationHandler) etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (sh SyndicationHandler) writeXML(c *gin.Context, contentType string, feed interface{}) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		sh.writeError(c, cerror.NewAndPrintWithTag("WXS00", err, global.FRIENDLY_MESSAGE))
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), body...))
+}
+
+func (sh SyndicationHandler) writeError(c *gin.Context, err error) {
+	cerr, ok := err.(cerror.Error)
+	if !ok {
+		cerr = cerror.NewAndPrintWithTag("WES00", err, global.FRIENDLY_MESSAGE)
+	}
+
+	httpStatus := http.StatusInternalServerError
+	if cerr.Type == cerror.TYPE_NOT_FOUND {
+		httpStatus = http.StatusNotFound
+	}
+
+	c.JSON(httpStatus, FeedErrorResponse{Message: cerr.FriendlyMessageWithTag()})
+}
+
+func (sh SyndicationHandler) postURL(profile domain.Profile, post domain.Post) string {
+	return fmt.Sprintf("%s/u/%s/posts/%s", config.Config.FEHost, profile.Username, post.PostID)
+}
+
+// postTitle uses the first line of the markdown source, posts have no title of their own
+func (sh SyndicationHandler) postTitle(post domain.Post) string {
+	title := strings.TrimSpace(strings.SplitN(post.Content, "\n", 2)[0])
+	title = strings.TrimSpace(strings.TrimLeft(title, "#>-* "))
+
+	if title == "" {
+		return post.Date.Format(global.TIME_FORMAT)
+	}
+
+	if utf8.RuneCountInString(title) > FEED_TITLE_CHARS {
+		title = string([]rune(title)[:FEED_TITLE_CHARS]) + "…"
+	}
+	return title
+}
+
+func (sh SyndicationHandler) postEnclosure(post domain.Post) *enclosure {
+	if post.ImageURL == "" {
+		return nil
+	}
+
+	image := &enclosure{
+		url:      config.Config.Host + post.ImageURL,
+		mimeType: mime.TypeByExtension(filepath.Ext(post.ImageURL)),
+	}
+	if image.mimeType == "" {
+		image.mimeType = "application/octet-stream"
+	}
+
+	path := strings.ReplaceAll(post.ImageURL, "/", string(os.PathSeparator))
+	info, err := os.Stat(global.WD + path)
+	if err == nil {
+		image.length = info.Size()
+	}
+
+	return image
+}
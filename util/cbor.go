@@ -0,0 +1,171 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DecodeCBOR decodes the first CBOR item of data and returns it with the
+// number of bytes it used. Only the definite length subset used by
+// WebAuthn is supported. Integers are returned as int64, byte strings as
+// []byte, text as string, arrays as []interface{} and maps as
+// map[interface{}]interface{}.
+func DecodeCBOR(data []byte) (interface{}, int, error) {
+	decoder := cborDecoder{data: data}
+	value, err := decoder.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, decoder.offset, nil
+}
+
+const cborMaxDepth = 16
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+
+	if d.offset >= len(d.data) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+
+	initial := d.data[d.offset]
+	d.offset++
+	major, info := initial>>5, initial&0x1f
+
+	//simple values and floats
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	}
+
+	argument, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return int64(argument), nil
+
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(argument), nil
+
+	case 2, 3:
+		b, err := d.bytes(argument)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(b), nil
+		}
+		return b, nil
+
+	case 4:
+		if argument > uint64(len(d.data)) {
+			return nil, errors.New("cbor: array length exceeds data")
+		}
+		array := make([]interface{}, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		return array, nil
+
+	case 5:
+		if argument > uint64(len(d.data)) {
+			return nil, errors.New("cbor: map length exceeds data")
+		}
+		m := make(map[interface{}]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("cbor: unsupported map key type")
+			}
+
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported major type %d", major)
+}
+
+// argument reads the length or value that follows the initial byte
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.bytes(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(b[0]), nil
+	case info == 25:
+		b, err := d.bytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.bytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.bytes(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+
+	return 0, errors.New("cbor: indefinite length is not supported")
+}
+
+func (d *cborDecoder) bytes(length uint64) ([]byte, error) {
+	if length > uint64(len(d.data)-d.offset) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+
+	b := d.data[d.offset : d.offset+int(length)]
+	d.offset += int(length)
+	return b, nil
+}
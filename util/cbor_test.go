@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		want     interface{}
+		consumed int
+	}{
+		{"small uint", []byte{0x17}, int64(23), 1},
+		{"uint8", []byte{0x18, 0xff}, int64(255), 2},
+		{"uint16", []byte{0x19, 0x01, 0x00}, int64(256), 3},
+		{"uint32", []byte{0x1a, 0x00, 0x01, 0x00, 0x00}, int64(65536), 5},
+		{"negative", []byte{0x26}, int64(-7), 1},
+		{"negative uint16", []byte{0x39, 0x01, 0x00}, int64(-257), 3},
+		{"byte string", []byte{0x43, 0x01, 0x02, 0x03}, []byte{1, 2, 3}, 4},
+		{"text", []byte{0x63, 'f', 'm', 't'}, "fmt", 4},
+		{"array", []byte{0x82, 0x01, 0x20}, []interface{}{int64(1), int64(-1)}, 3},
+		{"map", []byte{0xa2, 0x01, 0x02, 0x61, 'a', 0xf5}, map[interface{}]interface{}{int64(1): int64(2), "a": true}, 6},
+		{"false", []byte{0xf4}, false, 1},
+		{"null", []byte{0xf6}, nil, 1},
+		{"trailing data is not consumed", []byte{0x01, 0x02}, int64(1), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, consumed, err := DecodeCBOR(tt.data)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+			if consumed != tt.consumed {
+				t.Errorf("consumed = %d, want %d", consumed, tt.consumed)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"missing uint8", []byte{0x18}},
+		{"missing uint64 bytes", []byte{0x1b, 0x00, 0x00}},
+		{"uint64 overflow", []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"negative overflow", []byte{0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"short byte string", []byte{0x45, 0x01, 0x02}},
+		{"huge byte string", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge array", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge map", []byte{0xbb, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"short array", []byte{0x83, 0x01, 0x02}},
+		{"map missing value", []byte{0xa1, 0x01}},
+		{"byte string map key", []byte{0xa1, 0x41, 0x00, 0x01}},
+		{"indefinite byte string", []byte{0x5f, 0x41, 0x00, 0xff}},
+		{"indefinite map", []byte{0xbf, 0x01, 0x02, 0xff}},
+		{"float", []byte{0xfa, 0x3f, 0x80, 0x00, 0x00}},
+		{"tag", []byte{0xc0, 0x60}},
+		{"deep nesting", append(bytes.Repeat([]byte{0x81}, 32), 0x00)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value, _, err := DecodeCBOR(tt.data); err == nil {
+				t.Fatalf("decoded %#v without error", value)
+			}
+		})
+	}
+}
+
+// every prefix of a valid item is an error, never a panic
+func TestDecodeCBORTruncated(t *testing.T) {
+	data := []byte{
+		0xa3,
+		0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e',
+		0x01, 0x82, 0x19, 0x01, 0x00, 0x39, 0x01, 0x00,
+		0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x44, 0xde, 0xad, 0xbe, 0xef,
+	}
+	if _, consumed, err := DecodeCBOR(data); err != nil || consumed != len(data) {
+		t.Fatalf("consumed = %d, err = %v", consumed, err)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if _, _, err := DecodeCBOR(data[:i]); err == nil {
+			t.Errorf("item truncated to %d bytes is decoded", i)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"127.0.0.1", "10.0.0.0/8"}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{"direct", "203.0.113.7:51234", nil, "", "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:51234", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7"},
+		{"trusted proxy", "127.0.0.1:8080", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"client prepends a fake hop", "127.0.0.1:8080", []string{"1.2.3.4, 198.51.100.1"}, "", "198.51.100.1"},
+		{"proxy chain", "127.0.0.1:8080", []string{"198.51.100.1, 10.1.2.3"}, "", "198.51.100.1"},
+		{"split headers", "127.0.0.1:8080", []string{"1.2.3.4", "198.51.100.1"}, "", "198.51.100.1"},
+		{"real ip header is ignored", "127.0.0.1:8080", nil, "198.51.100.2", "127.0.0.1"},
+		{"garbage hop", "127.0.0.1:8080", []string{"198.51.100.1, not-an-ip"}, "", "127.0.0.1"},
+		{"only trusted hops", "127.0.0.1:8080", []string{"10.0.0.1"}, "", "10.0.0.1"},
+		{"ipv6 peer", "[2001:db8::1]:443", []string{"198.51.100.1"}, "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: http.Header{}}
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := ClientIP(r, trusted); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package util
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash encodes a coordinate into a geohash of the given precision
+func EncodeGeohash(latitude, longitude float64, precision int) string {
+	var (
+		minLat, maxLat = -90.0, 90.0
+		minLon, maxLon = -180.0, 180.0
+		hash           = make([]byte, 0, precision)
+		bit, ch        = 0, 0
+		evenBit        = true
+	)
+
+	for len(hash) < precision {
+		if evenBit {
+			mid := (minLon + maxLon) / 2
+			if longitude >= mid {
+				ch = ch<<1 | 1
+				minLon = mid
+			} else {
+				ch = ch << 1
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if latitude >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch = ch << 1
+				maxLat = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bit++
+		if bit == 5 {
+			hash = append(hash, geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	ARGON2_PREFIX     = "$argon2id$"
+	ARGON2_KEY_LENGTH = 32
+)
+
+// Argon2Params Memory is in KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+}
+
+// HashArgon2id returns the hash in the PHC string format, which carries
+// everything needed to verify it:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func HashArgon2id(password, salt []byte, params Argon2Params) string {
+	key := argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, ARGON2_KEY_LENGTH)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		ARGON2_PREFIX, argon2.Version,
+		params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+// VerifyArgon2id compares password with an encoded hash in constant time
+func VerifyArgon2id(password []byte, encoded string) (bool, error) {
+	params, salt, key, err := parseArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	inputKey := argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, inputKey) == 1, nil
+}
+
+// Argon2idNeedsRehash is true when encoded is not an argon2id hash created
+// with params
+func Argon2idNeedsRehash(encoded string, params Argon2Params) bool {
+	hashParams, _, key, err := parseArgon2id(encoded)
+	return err != nil || hashParams != params || len(key) != ARGON2_KEY_LENGTH
+}
+
+func IsArgon2id(encoded string) bool {
+	return strings.HasPrefix(encoded, ARGON2_PREFIX)
+}
+
+func parseArgon2id(encoded string) (params Argon2Params, salt, key []byte, err error) {
+	var version int
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || "$"+parts[1]+"$" != ARGON2_PREFIX {
+		err = errors.New("invalid argon2id hash format")
+		return
+	}
+
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return
+	}
+
+	if version != argon2.Version {
+		err = fmt.Errorf("unsupported argon2 version %d", version)
+		return
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return
+	}
+
+	//Sscanf ignores trailing input, and argon2 panics on zero parallelism
+	if parts[3] != fmt.Sprintf("m=%d,t=%d,p=%d", params.Memory, params.Iterations, params.Parallelism) ||
+		params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		err = fmt.Errorf("invalid argon2id parameters %q", parts[3])
+		return
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return
+	}
+
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err == nil && len(key) == 0 {
+		err = errors.New("argon2id hash is empty")
+	}
+
+	return
+}
@@ -0,0 +1,276 @@
+package util
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// password strength scores, following the zxcvbn scale
+const (
+	PASSWORD_SCORE_TOO_GUESSABLE = iota
+	PASSWORD_SCORE_VERY_GUESSABLE
+	PASSWORD_SCORE_SOMEWHAT_GUESSABLE
+	PASSWORD_SCORE_SAFELY_UNGUESSABLE
+	PASSWORD_SCORE_VERY_UNGUESSABLE
+)
+
+const minPatternLength = 3
+
+// commonPasswords is ordered by popularity, the rank is used as the number
+// of guesses
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "admin", "iloveyou",
+	"monkey", "dragon", "football", "baseball", "sunshine", "princess", "master",
+	"shadow", "superman", "michael", "login", "abc123", "trustno1", "starwars",
+	"hello", "freedom", "whatever", "ninja", "mustang", "access", "batman",
+	"charlie", "secret", "summer", "winter", "spring", "autumn", "flower",
+	"soccer", "hockey", "killer", "jordan", "hunter", "ranger", "buster",
+	"thomas", "tigger", "robert", "daniel", "computer", "internet", "love",
+	"lovely", "angel", "jesus", "pokemon", "cheese", "coffee", "chocolate",
+	"maggie", "ginger", "pepper", "cookie", "purple", "orange", "banana",
+	"apple", "family", "friend", "friends", "forever", "happy", "money",
+	"matrix", "google", "samsung", "liverpool", "chelsea", "arsenal", "london",
+	"jakarta", "indonesia", "bandung", "sayang", "rahasia", "bismillah",
+	"changeme", "default", "passw0rd", "p@ssw0rd", "qwertyuiop", "asdfgh",
+	"zxcvbn", "mymoment", "moment", "diary", "journal", "memory", "memories",
+}
+
+var keyboardRows = []string{
+	"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+}
+
+var leetSubstitutions = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's', '!': 'i',
+}
+
+// PasswordStrength estimates how hard password is to guess on the zxcvbn
+// scale of 0 to 4. The password is covered with the cheapest sequence of
+// known patterns (common passwords, userInputs, repeats, sequences,
+// keyboard walks and years) and brute forced characters
+func PasswordStrength(password string, userInputs ...string) int {
+	guesses := passwordGuesses(password, userInputs)
+
+	switch log := math.Log10(guesses); {
+	case log < 3:
+		return PASSWORD_SCORE_TOO_GUESSABLE
+	case log < 6:
+		return PASSWORD_SCORE_VERY_GUESSABLE
+	case log < 8:
+		return PASSWORD_SCORE_SOMEWHAT_GUESSABLE
+	case log < 10:
+		return PASSWORD_SCORE_SAFELY_UNGUESSABLE
+	default:
+		return PASSWORD_SCORE_VERY_UNGUESSABLE
+	}
+}
+
+func passwordGuesses(password string, userInputs []string) float64 {
+	original := []rune(password)
+	if len(original) == 0 {
+		return 1
+	}
+
+	//a few runes change length when lowered, those are matched as typed
+	runes := []rune(strings.ToLower(password))
+	if len(runes) != len(original) {
+		runes = original
+	}
+	leet := []rune(unleet(string(runes)))
+
+	dictionary := make(map[string]float64)
+	for i, word := range commonPasswords {
+		dictionary[word] = float64(i + 1)
+	}
+	for _, input := range userInputs {
+		for _, word := range strings.FieldsFunc(strings.ToLower(input), isSeparator) {
+			if len([]rune(word)) >= minPatternLength {
+				dictionary[word] = 1
+			}
+		}
+	}
+
+	cardinality := float64(passwordCardinality(original))
+
+	//best[i] is the fewest guesses to cover runes[:i], each extra segment
+	//multiplies by its count like the zxcvbn sequence penalty
+	best := make([]float64, len(runes)+1)
+	segments := make([]int, len(runes)+1)
+	best[0] = 1
+	for end := 1; end <= len(runes); end++ {
+		best[end] = best[end-1] * cardinality
+		segments[end] = segments[end-1] + 1
+
+		for start := 0; start <= end-minPatternLength; start++ {
+			guesses := patternGuesses(runes[start:end], leet[start:end], original[start:end], dictionary)
+			if guesses == 0 {
+				continue
+			}
+
+			total := best[start] * guesses * float64(segments[start]+1)
+			if total < best[end] {
+				best[end] = total
+				segments[end] = segments[start] + 1
+			}
+		}
+	}
+
+	return best[len(runes)]
+}
+
+// patternGuesses returns zero when token does not match any pattern, leet
+// is token with l33t substitutions undone
+func patternGuesses(token, leet, original []rune, dictionary map[string]float64) float64 {
+	var (
+		word    = string(token)
+		length  = float64(len(token))
+		guesses float64
+	)
+
+	if rank, ok := dictionary[word]; ok {
+		guesses = rank * caseVariations(original)
+	} else if rank, ok := dictionary[string(leet)]; ok {
+		guesses = rank * caseVariations(original) * 2
+	}
+
+	candidates := []float64{
+		repeatGuesses(token, length),
+		sequenceGuesses(token, length),
+		keyboardGuesses(word, length),
+		yearGuesses(word),
+	}
+	for _, candidate := range candidates {
+		if candidate > 0 && (guesses == 0 || candidate < guesses) {
+			guesses = candidate
+		}
+	}
+
+	return guesses
+}
+
+func repeatGuesses(token []rune, length float64) float64 {
+	for _, r := range token[1:] {
+		if r != token[0] {
+			return 0
+		}
+	}
+	return float64(passwordCardinality(token[:1])) * length
+}
+
+func sequenceGuesses(token []rune, length float64) float64 {
+	delta := token[1] - token[0]
+	if delta != 1 && delta != -1 {
+		return 0
+	}
+
+	for i := 2; i < len(token); i++ {
+		if token[i]-token[i-1] != delta {
+			return 0
+		}
+	}
+
+	//sequences starting at an obvious character are tried first
+	base := 26.0
+	if unicode.IsDigit(token[0]) {
+		base = 10
+	}
+	if token[0] == 'a' || token[0] == 'z' || token[0] == '0' || token[0] == '1' {
+		base = 4
+	}
+	return base * length
+}
+
+func keyboardGuesses(word string, length float64) float64 {
+	for _, row := range keyboardRows {
+		if strings.Contains(row, word) || strings.Contains(reverse(row), word) {
+			return 100 * length
+		}
+	}
+	return 0
+}
+
+func yearGuesses(word string) float64 {
+	if len(word) == 4 && (strings.HasPrefix(word, "19") || strings.HasPrefix(word, "20")) &&
+		strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return 120
+	}
+	return 0
+}
+
+// caseVariations doubles the guesses of a word for each common way of
+// capitalizing it
+func caseVariations(original []rune) float64 {
+	upper := 0
+	for _, r := range original {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case upper == len(original), upper == 1 && unicode.IsUpper(original[0]):
+		return 2
+	default:
+		return math.Pow(2, float64(upper))
+	}
+}
+
+func passwordCardinality(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < 128:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	cardinality := 0
+	if lower {
+		cardinality += 26
+	}
+	if upper {
+		cardinality += 26
+	}
+	if digit {
+		cardinality += 10
+	}
+	if symbol {
+		cardinality += 33
+	}
+	if other {
+		cardinality += 100
+	}
+	return cardinality
+}
+
+func unleet(s string) string {
+	return strings.Map(func(r rune) rune {
+		if substitute, ok := leetSubstitutions[r]; ok {
+			return substitute
+		}
+		return r
+	}, s)
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,53 @@
+package util
+
+import "testing"
+
+func TestPasswordStrength(t *testing.T) {
+	userInputs := []string{"budi@example.com", "Budi Santoso"}
+
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		want       int
+	}{
+		{"empty", "", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"common password", "password", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"common password with capitals", "Password", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"leet", "P@ssw0rd", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"leet dictionary word", "L1verp00l", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"leet with a sequence", "p4ssw0rd123", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"keyboard row", "qwertyuiop", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"keyboard row with symbol", "asdfghjkl;", nil, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"keyboard column", "1qaz2wsx3edc", nil, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"repeat", "aaaaaaaaaaaa", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"sequence", "abcdefghij", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"descending sequence", "9876543210", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"year", "1987", nil, PASSWORD_SCORE_TOO_GUESSABLE},
+		{"word and year", "summer2019", nil, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"word year and symbol", "dragon1990!", nil, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"name and year", "budi.santoso1990", userInputs, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"names swapped", "santosoBudi!!", userInputs, PASSWORD_SCORE_VERY_GUESSABLE},
+		{"random", "xK2pQ9vLm4Rt", nil, PASSWORD_SCORE_VERY_UNGUESSABLE},
+		{"random with symbols", "kX9#vQ2!mZ7@", nil, PASSWORD_SCORE_VERY_UNGUESSABLE},
+		{"passphrase", "correct horse battery staple", nil, PASSWORD_SCORE_VERY_UNGUESSABLE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PasswordStrength(tt.password, tt.userInputs...); got != tt.want {
+				t.Errorf("PasswordStrength(%q) = %d, want %d", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordStrengthUserInputs(t *testing.T) {
+	password := "budi.santoso1990"
+	without := PasswordStrength(password)
+	with := PasswordStrength(password, "budi@example.com", "Budi Santoso")
+
+	if with >= without {
+		t.Errorf("score with user inputs = %d, want below %d", with, without)
+	}
+}
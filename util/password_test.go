@@ -0,0 +1,103 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+// testArgon2Params keeps the tests fast, the parameters are not what
+// production uses
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	encoded := HashArgon2id([]byte("correct horse"), []byte("0123456789abcdef"), testArgon2Params)
+
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") || !IsArgon2id(encoded) {
+		t.Fatalf("encoded = %s", encoded)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"right password", "correct horse", true},
+		{"wrong password", "correct horsf", false},
+		{"empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyArgon2id([]byte(tt.password), encoded)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if ok != tt.ok {
+				t.Errorf("VerifyArgon2id = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	encoded := HashArgon2id([]byte("correct horse"), []byte("0123456789abcdef"), testArgon2Params)
+
+	tests := []struct {
+		name    string
+		encoded string
+		params  Argon2Params
+		want    bool
+	}{
+		{"same params", encoded, testArgon2Params, false},
+		{"memory changed", encoded, Argon2Params{Memory: 128, Iterations: 1, Parallelism: 1}, true},
+		{"iterations changed", encoded, Argon2Params{Memory: 64, Iterations: 2, Parallelism: 1}, true},
+		{"parallelism changed", encoded, Argon2Params{Memory: 64, Iterations: 1, Parallelism: 2}, true},
+		{"legacy bcrypt", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", testArgon2Params, true},
+		{"short key", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA", testArgon2Params, true},
+		{"malformed", "$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$aGFzaA", testArgon2Params, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Argon2idNeedsRehash(tt.encoded, tt.params); got != tt.want {
+				t.Errorf("Argon2idNeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyArgon2idMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"legacy bcrypt", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"},
+		{"argon2i", "$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{"other version", "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{"missing version", "$argon2id$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{"missing hash", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ"},
+		{"extra part", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA$aGFzaA"},
+		{"missing param", "$argon2id$v=19$m=64,t=1$c2FsdHNhbHQ$aGFzaA"},
+		{"trailing param", "$argon2id$v=19$m=64,t=1,p=1,x=1$c2FsdHNhbHQ$aGFzaA"},
+		{"zero memory", "$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{"zero iterations", "$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{"zero parallelism", "$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$aGFzaA"},
+		{"parallelism out of range", "$argon2id$v=19$m=64,t=1,p=256$c2FsdHNhbHQ$aGFzaA"},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$c2Fsd!$aGFzaA"},
+		{"bad hash", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$aGFz!"},
+		{"empty hash", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyArgon2id([]byte("password"), tt.encoded)
+			if err == nil || ok {
+				t.Errorf("VerifyArgon2id = %v, %v, want an error", ok, err)
+			}
+			if !Argon2idNeedsRehash(tt.encoded, testArgon2Params) {
+				t.Error("Argon2idNeedsRehash = false, want true")
+			}
+		})
+	}
+}
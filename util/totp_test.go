@@ -0,0 +1,85 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the base32 of the RFC 6238 appendix B SHA-1 seed
+// "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	//RFC 6238 appendix B SHA-1 vectors truncated to 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	codeAt := func(step int64) string {
+		code, err := TOTPCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+		step   int64
+	}{
+		{"current step", rfcSecret, codeAt(current), true, current},
+		{"previous step", rfcSecret, codeAt(current - 1), true, current - 1},
+		{"next step", rfcSecret, codeAt(current + 1), true, current + 1},
+		{"two steps behind", rfcSecret, codeAt(current - 2), false, 0},
+		{"two steps ahead", rfcSecret, codeAt(current + 2), false, 0},
+		{"wrong code", rfcSecret, "000000", false, 0},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", codeAt(current), true, current},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := ValidateTOTP(tt.secret, tt.code, now)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if ok != tt.ok || step != tt.step {
+				t.Errorf("ValidateTOTP = %d, %v, want %d, %v", step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInvalidSecret(t *testing.T) {
+	_, ok, err := ValidateTOTP("not base32!", "123456", time.Unix(59, 0))
+	if err == nil || ok {
+		t.Errorf("ValidateTOTP = %v, %v, want an error", ok, err)
+	}
+}
@@ -0,0 +1,61 @@
+package util
+
+import "strings"
+
+// browsers are checked in order, most browsers also name the engine of
+// the one they are based on
+var userAgentBrowsers = []struct{ token, name string }{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"okhttp/", "Android app"},
+	{"curl/", "curl"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"iphone", "iPhone"},
+	{"ipad", "iPad"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"mac os x", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+// DeviceName returns a short description such as "Chrome on Windows" from
+// a User-Agent header
+func DeviceName(userAgent string) string {
+	userAgent = strings.ToLower(userAgent)
+
+	browser := ""
+	for _, candidate := range userAgentBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	system := ""
+	for _, candidate := range userAgentSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}